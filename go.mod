@@ -1,6 +1,6 @@
 module github.com/foxcapades/go-wildcard-env
 
-go 1.21
+go 1.23
 
 require github.com/smartystreets/goconvey v1.8.1
 
@@ -0,0 +1,378 @@
+package wenv
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bind fills a new value of type T for every MatchGroupResult found for the
+// named MatchGroup in the given EnvMatchResult.
+//
+// T must be a struct type.  The struct's fields are bound to KeyMatcher names
+// using the `wenv` struct tag.  Fields without a `wenv` tag, or with the tag
+// value "-", are left untouched.
+//
+// The tag value is the name of the KeyMatcher whose value should be bound to
+// the field, optionally followed by a comma separated list of options:
+//   required     The field must have a value, either from the environment or
+//                from a default.
+//   sep=<sep>    The separator used to split values for slice fields.  Defaults
+//                to ",".
+//   secret       The value is redacted in any *ConversionError returned by
+//                Bind.  Groups built by GroupFromStruct also mark the
+//                KeyMatcher as secret (see MatchGroup.SetSecret), so the value
+//                is redacted when their results are serialized.
+//   default=<v>  The value to use if the KeyMatcher did not match for a given
+//                MatchGroupResult.  As the default value may itself contain
+//                commas, this option must come last.
+//
+// Fields may be strings, bools, signed or unsigned integers, floats,
+// time.Duration, url.URL, pointers to or slices of any of these, or any type
+// that implements encoding.TextUnmarshaler.  Values are parsed following the
+// same rules as the typed accessors on MatchGroupResult.  If T has a tagged
+// field of any other type, Bind returns an error without binding anything.
+//
+// If the named MatchGroup has no results, Bind returns a nil slice and a nil
+// error.
+//
+// Bind attempts to fill every field of every value, collecting all the errors
+// it encounters along the way.  If any errors were encountered, they are
+// returned as a MatcherErrors value alongside the successfully bound values.
+// Values that could not be converted are reported as *ConversionError values,
+// and missing required values are reported as *MissingKeyError values.
+//
+// Example:
+//   type Database struct {
+//     Address string `wenv:"address,required"`
+//     Port    int    `wenv:"port,default=5432"`
+//   }
+//
+//   databases, err := Bind[Database](envResult, "db")
+func Bind[T any](result EnvMatchResult, group string) ([]T, error) {
+	plan, err := bindPlanFor(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	results := result.Get(group)
+	if results == nil {
+		return nil, nil
+	}
+
+	var errors MatcherErrors
+	out := make([]T, results.Size())
+
+	for i := range out {
+		errors = append(errors, plan.bind(reflect.ValueOf(&out[i]).Elem(), results.Get(i))...)
+	}
+
+	if errors.HasErrors() {
+		return out, errors
+	}
+
+	return out, nil
+}
+
+// BindMap works like Bind, but returns the bound values in a map keyed on the
+// FirstKey of the MatchGroupResult each value was bound from.
+//
+// Example:
+//   databases, err := BindMap[Database](envResult, "db")
+//   mainDB := databases["MAIN"]
+func BindMap[T any](result EnvMatchResult, group string) (map[string]T, error) {
+	plan, err := bindPlanFor(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	results := result.Get(group)
+	if results == nil {
+		return nil, nil
+	}
+
+	var errors MatcherErrors
+	out := make(map[string]T, results.Size())
+
+	for i := 0; i < results.Size(); i++ {
+		var value T
+		res := results.Get(i)
+
+		errors = append(errors, plan.bind(reflect.ValueOf(&value).Elem(), res)...)
+		out[res.FirstKey()] = value
+	}
+
+	if errors.HasErrors() {
+		return out, errors
+	}
+
+	return out, nil
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Struct Tags
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+const tagName = "wenv"
+
+type fieldTag struct {
+	name       string
+	required   bool
+	hasDefault bool
+	def        string
+	sep        string
+	secret     bool
+}
+
+// parseFieldTag parses the `wenv` struct tag on the given field.  If the field
+// has no tag or should be skipped, ok will be false.
+func parseFieldTag(field reflect.StructField) (tag fieldTag, ok bool, err error) {
+	raw, found := field.Tag.Lookup(tagName)
+	if !found || raw == "-" || !field.IsExported() {
+		return
+	}
+
+	tag.sep = ","
+
+	name, opts, _ := strings.Cut(raw, ",")
+	if name == "" {
+		err = fmt.Errorf("field %s has an empty %s tag name", field.Name, tagName)
+		return
+	}
+
+	tag.name = name
+
+	for opts != "" {
+		var opt string
+
+		// The default option consumes the remainder of the tag.
+		if strings.HasPrefix(opts, "default=") {
+			tag.hasDefault = true
+			tag.def = opts[len("default="):]
+			break
+		}
+
+		opt, opts, _ = strings.Cut(opts, ",")
+
+		switch {
+		case opt == "required":
+			tag.required = true
+		case opt == "secret":
+			tag.secret = true
+		case strings.HasPrefix(opt, "sep="):
+			tag.sep = opt[len("sep="):]
+		default:
+			err = fmt.Errorf("field %s has unrecognized %s tag option %q", field.Name, tagName, opt)
+			return
+		}
+	}
+
+	ok = true
+	return
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Bind Plan
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+type bindField struct {
+	index []int
+	field string
+	tag   fieldTag
+}
+
+type bindPlan struct {
+	fields []bindField
+}
+
+func bindPlanFor(typ reflect.Type) (*bindPlan, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot bind to non-struct type %s", typ)
+	}
+
+	out := &bindPlan{fields: make([]bindField, 0, typ.NumField())}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		tag, ok, err := parseFieldTag(field)
+		if err != nil {
+			return nil, fmt.Errorf("cannot bind to type %s: %w", typ, err)
+		} else if !ok {
+			continue
+		}
+
+		if !convertible(field.Type) {
+			return nil, fmt.Errorf("cannot bind to type %s: field %s has unsupported type %s", typ, field.Name, field.Type)
+		}
+
+		out.fields = append(out.fields, bindField{field.Index, field.Name, tag})
+	}
+
+	return out, nil
+}
+
+func (b *bindPlan) bind(target reflect.Value, res MatchGroupResult) []error {
+	var errors []error
+
+	for _, field := range b.fields {
+		var value, raw string
+
+		if res.Has(field.tag.name) {
+			match := res.Get(field.tag.name)
+			value, raw = match.Value(), match.Raw()
+		} else if field.tag.hasDefault {
+			value = field.tag.def
+		} else if field.tag.required {
+			errors = append(errors, &MissingKeyError{
+				Group:    res.Name(),
+				Keys:     res.Keys(),
+				Matcher:  field.tag.name,
+				Variable: res.ExpectedName(field.tag.name),
+				Field:    field.field,
+				Required: true,
+			})
+			continue
+		} else {
+			continue
+		}
+
+		fieldValue := target.FieldByIndex(field.index)
+
+		if err := convertInto(fieldValue, value, field.tag.sep); err != nil {
+			errors = append(errors, (&ConversionError{
+				Group:    res.Name(),
+				Keys:     res.Keys(),
+				Matcher:  field.tag.name,
+				Variable: raw,
+				Field:    field.field,
+				Value:    value,
+				Secret:   field.tag.secret || isSecret(res, field.tag.name),
+				Type:     fieldValue.Type().String(),
+				Err:      err,
+			}).redact())
+		}
+	}
+
+	return errors
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Conversion
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+)
+
+// convertible returns whether values can be parsed into the given type by
+// convertInto.
+func convertible(typ reflect.Type) bool {
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) || typ == durationType || typ == urlType {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer, reflect.Slice:
+		return convertible(typ.Elem())
+	}
+
+	return false
+}
+
+// convertInto parses the given value into the given target.
+func convertInto(target reflect.Value, value, sep string) error {
+	typ := target.Type()
+
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch typ {
+	case durationType:
+		d, err := parseDuration(value)
+		if err == nil {
+			target.SetInt(int64(d))
+		}
+		return err
+
+	case urlType:
+		u, err := parseURL(value)
+		if err == nil {
+			target.Set(reflect.ValueOf(*u))
+		}
+		return err
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		target.SetString(value)
+		return nil
+
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err == nil {
+			target.SetBool(b)
+		}
+		return err
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, typ.Bits())
+		if err == nil {
+			target.SetInt(i)
+		}
+		return err
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, typ.Bits())
+		if err == nil {
+			target.SetUint(u)
+		}
+		return err
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typ.Bits())
+		if err == nil {
+			target.SetFloat(f)
+		}
+		return err
+
+	case reflect.Pointer:
+		ptr := reflect.New(typ.Elem())
+		if err := convertInto(ptr.Elem(), value, sep); err != nil {
+			return err
+		}
+		target.Set(ptr)
+		return nil
+
+	case reflect.Slice:
+		parts := parseStringSlice(value, sep)
+		slice := reflect.MakeSlice(typ, len(parts), len(parts))
+		for i, part := range parts {
+			if err := convertInto(slice.Index(i), part, sep); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		target.Set(slice)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported field type %s", typ)
+}
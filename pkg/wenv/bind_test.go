@@ -0,0 +1,220 @@
+package wenv_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+type bindTestDB struct {
+	Address  string        `wenv:"address,required"`
+	Port     int           `wenv:"port,default=5432"`
+	TLS      bool          `wenv:"tls"`
+	Timeout  time.Duration `wenv:"timeout"`
+	URL      url.URL       `wenv:"url"`
+	IP       net.IP        `wenv:"ip"`
+	Tags     []string      `wenv:"tags,sep=;"`
+	Weights  []float64     `wenv:"weights"`
+	PoolSize *uint16       `wenv:"pool"`
+	Ignored  string
+	Skipped  string `wenv:"-"`
+}
+
+func bindTestMatcher() wenv.EnvironmentMatcher {
+	group := wenv.NewMatchGroup("db")
+
+	for _, name := range []string{"address", "port", "tls", "timeout", "url", "ip", "tags", "weights", "pool"} {
+		group.AddMatcher(wenv.NewPatternMatcher(name, "DB_{id}_"+name), false)
+	}
+
+	return wenv.NewEnvironmentMatcher().AddGroup(group, true)
+}
+
+func TestBind(t *testing.T) {
+	Convey("Bind", t, func() {
+		Convey("with valid values", func() {
+			result := bindTestMatcher().ParseEnv(map[string]string{
+				"DB_FOO_address": "somehost",
+				"DB_FOO_port":    "1234",
+				"DB_FOO_tls":     "true",
+				"DB_FOO_timeout": "5s",
+				"DB_FOO_url":     "postgres://somehost:1234/db",
+				"DB_FOO_ip":      "10.0.0.1",
+				"DB_FOO_tags":    "a; b;c",
+				"DB_FOO_weights": "0.5,1.5",
+				"DB_FOO_pool":    "12",
+				"DB_BAR_address": "otherhost",
+			})
+
+			dbs, err := wenv.BindMap[bindTestDB](result, "db")
+
+			So(err, ShouldBeNil)
+			So(len(dbs), ShouldEqual, 2)
+
+			foo := dbs["FOO"]
+			So(foo.Address, ShouldEqual, "somehost")
+			So(foo.Port, ShouldEqual, 1234)
+			So(foo.TLS, ShouldBeTrue)
+			So(foo.Timeout, ShouldEqual, 5*time.Second)
+			So(foo.URL.Host, ShouldEqual, "somehost:1234")
+			So(foo.IP.String(), ShouldEqual, "10.0.0.1")
+			So(foo.Tags, ShouldResemble, []string{"a", "b", "c"})
+			So(foo.Weights, ShouldResemble, []float64{0.5, 1.5})
+			So(*foo.PoolSize, ShouldEqual, 12)
+
+			bar := dbs["BAR"]
+			So(bar.Address, ShouldEqual, "otherhost")
+			So(bar.Port, ShouldEqual, 5432)
+			So(bar.PoolSize, ShouldBeNil)
+
+			list, err := wenv.Bind[bindTestDB](result, "db")
+			So(err, ShouldBeNil)
+			So(len(list), ShouldEqual, 2)
+		})
+
+		Convey("with invalid values", func() {
+			result := bindTestMatcher().ParseEnv(map[string]string{
+				"DB_FOO_port":    "abc",
+				"DB_FOO_timeout": "5 parsecs",
+			})
+
+			dbs, err := wenv.Bind[bindTestDB](result, "db")
+
+			So(len(dbs), ShouldEqual, 1)
+			So(err, ShouldNotBeNil)
+
+			errs := err.(wenv.MatcherErrors)
+			So(errs.Size(), ShouldEqual, 3)
+			So(errs.Get(0).Error(), ShouldEqual, "match group db (keys: FOO) has no value for required field Address (key address): expected variable DB_FOO_address")
+			So(errs.Get(1).Error(), ShouldStartWith, "match group db (keys: FOO): cannot convert DB_FOO_port (\"abc\") to int for field Port: ")
+			So(errs.Get(2).Error(), ShouldStartWith, "match group db (keys: FOO): cannot convert DB_FOO_timeout (\"5 parsecs\") to time.Duration for field Timeout: ")
+
+			convErr := errs.Get(1).(*wenv.ConversionError)
+			So(convErr.Variable, ShouldEqual, "DB_FOO_port")
+			So(convErr.Matcher, ShouldEqual, "port")
+			So(convErr.Keys, ShouldResemble, []string{"FOO"})
+		})
+
+		Convey("with a missing group", func() {
+			dbs, err := wenv.Bind[bindTestDB](bindTestMatcher().ParseEnv(map[string]string{}), "db")
+
+			So(dbs, ShouldBeNil)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("with an invalid target type", func() {
+			type badTag struct {
+				Value string `wenv:"value,optional"`
+			}
+
+			_, err := wenv.Bind[string](bindTestMatcher().ParseEnv(map[string]string{}), "db")
+			So(err, ShouldNotBeNil)
+
+			_, err = wenv.Bind[badTag](bindTestMatcher().ParseEnv(map[string]string{}), "db")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("with an unsupported field type", func() {
+			type badType struct {
+				Value map[string]string `wenv:"value"`
+			}
+
+			_, err := wenv.Bind[badType](bindTestMatcher().ParseEnv(map[string]string{}), "db")
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, wenv.ErrConversion), ShouldBeFalse)
+			So(err.Error(), ShouldEqual, "cannot bind to type wenv_test.badType: field Value has unsupported type map[string]string")
+
+			_, err = wenv.ParseGroupFromStruct[badType]("db", wenv.Prefix("DB_{field}_"))
+			So(err, ShouldNotBeNil)
+
+			type badElem struct {
+				Values []chan int `wenv:"values"`
+			}
+
+			_, err = wenv.BindMap[badElem](bindTestMatcher().ParseEnv(map[string]string{}), "db")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestGroupFromStruct(t *testing.T) {
+	Convey("GroupFromStruct", t, func() {
+		type database struct {
+			Address string `wenv:"ADDRESS,required"`
+			Port    int    `wenv:"PORT,required,default=5432"`
+			User    string `wenv:"USER"`
+		}
+
+		Convey("with a valid struct", func() {
+			result := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.GroupFromStruct[database]("db", wenv.Wrapped("DB_", "_{field}")), true).
+				ParseEnv(map[string]string{
+					"DB_FOO_ADDRESS": "somehost",
+					"DB_FOO_PORT":    "1234",
+					"DB_BAR_ADDRESS": "otherhost",
+					"DB_BAZ_USER":    "someone",
+				})
+
+			So(result.Errors().Size(), ShouldEqual, 1)
+			So(result.Errors().Get(0).Error(), ShouldEqual, "match group db (keys: BAZ) does not have a match for required key ADDRESS: expected variable DB_BAZ_ADDRESS")
+
+			dbs, err := wenv.BindMap[database](result, "db")
+
+			So(err, ShouldNotBeNil)
+			So(dbs["FOO"], ShouldResemble, database{"somehost", 1234, ""})
+			So(dbs["BAR"], ShouldResemble, database{"otherhost", 5432, ""})
+		})
+
+		Convey("with each layout", func() {
+			layouts := map[wenv.FieldLayout]string{
+				wenv.Prefix("DB_{field}_"):            "DB_ADDRESS_FOO",
+				wenv.Suffix("_DB_{field}"):            "FOO_DB_ADDRESS",
+				wenv.Wrapped("DB_", "_{field}"):       "DB_FOO_ADDRESS",
+				wenv.Pattern("DB_{instance}_{field}"): "DB_FOO_ADDRESS",
+			}
+
+			for layout, variable := range layouts {
+				result := wenv.NewEnvironmentMatcher().
+					AddGroup(wenv.GroupFromStruct[database]("db", layout), true).
+					ParseEnv(map[string]string{variable: "somehost"})
+
+				So(result.Errors(), ShouldBeNil)
+				So(result.Get("db").Get(0).FirstKey(), ShouldEqual, "FOO")
+				So(result.Get("db").Get(0).Value("ADDRESS"), ShouldEqual, "somehost")
+			}
+		})
+
+		Convey("with an invalid layout", func() {
+			_, err := wenv.ParseGroupFromStruct[database]("db", wenv.Pattern("DB_{field}"))
+			So(err, ShouldNotBeNil)
+
+			So(func() { wenv.GroupFromStruct[database]("db", wenv.Pattern("DB_{field}")) }, ShouldPanic)
+		})
+
+		Convey("with a layout missing the field placeholder", func() {
+			layouts := []wenv.FieldLayout{
+				wenv.Prefix("DB_"),
+				wenv.Suffix("_DB"),
+				wenv.Wrapped("DB_", "_ADDRESS"),
+				wenv.Pattern("DB_{instance}"),
+			}
+
+			for _, layout := range layouts {
+				_, err := wenv.ParseGroupFromStruct[database]("db", layout)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "{field}")
+
+				So(func() { wenv.GroupFromStruct[database]("db", layout) }, ShouldPanic)
+			}
+
+			_, err := wenv.ParseGroupFromStruct[database]("db", wenv.Wrapped("DB_{field}_", ""))
+			So(err, ShouldBeNil)
+		})
+	})
+}
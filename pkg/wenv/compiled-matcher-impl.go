@@ -0,0 +1,208 @@
+package wenv
+
+type compiledEnvironmentMatcher struct {
+	groups   []*compiledMatchGroup
+	required []bool
+
+	// typos holds the typo detection options, if enabled.
+	typos *TypoOptions
+
+	// namespaces contains the variable name prefixes claimed by the matcher and
+	// its groups.
+	namespaces []namespace
+	strict     StrictMode
+	exclusive  bool
+
+	// order contains the indices of the groups in the order they are given the
+	// environment variables.
+	order []int
+
+	// index is used to find the KeyMatchers that may match each variable.  If
+	// nil, every variable is checked against every KeyMatcher.
+	index *matcherIndex
+
+	// warnings contains the problems found while compiling the matcher, which
+	// are included in the warnings of every result.
+	warnings []error
+}
+
+func (c *compiledEnvironmentMatcher) Warnings() MatcherErrors {
+	return c.warnings
+}
+
+func (c *compiledEnvironmentMatcher) ParseEnv(env map[string]string) EnvMatchResult {
+	return c.parse(mapVariables(env, mapSourceName), nil)
+}
+
+func (c *compiledEnvironmentMatcher) Parse(sources ...Source) EnvMatchResult {
+	vars, errs := mergeLayers(sources)
+	return c.parse(vars, errs)
+}
+
+// parse matches the given variables against the compiled MatchGroups.  Any
+// errors given will be included in the errors of the returned result.
+func (c *compiledEnvironmentMatcher) parse(env []Variable, loadErrors []error) EnvMatchResult {
+	result := &envMatchResult{
+		results: make(map[string]MatchGroupResults),
+	}
+
+	if len(c.warnings) > 0 {
+		result.warnings = append(result.warnings, c.warnings...)
+	}
+
+	// Make an error slice to contain any errors we encounter.
+	// We don't set this on the result right now so we don't waste mem on an empty
+	// slice if there are no errors.  In the case that there _are_ errors, then we
+	// will set this error list on the result.  In the case that there are not any
+	// errors, then the result's errors ref will remain nil.
+	errors := make([]error, 0, 8)
+	errors = append(errors, loadErrors...)
+
+	// When typo detection is enabled, namespaces have been claimed or variables
+	// are consumed exclusively, track which variables were matched by any group.
+	var matched []bool
+	if c.typos != nil || len(c.namespaces) > 0 || c.exclusive {
+		matched = make([]bool, len(env))
+	}
+
+	if c.exclusive {
+		result.claims = make(map[string]string, len(env))
+	}
+
+	// Each group gets a fresh state map for every parse so that no state is
+	// shared between calls.
+	states := make([]matchGroupMap, len(c.groups))
+	for i := range states {
+		states[i] = newMatchGroupMap()
+	}
+
+	// Each variable is visited once, and only offered to the KeyMatchers that
+	// may match it.
+	var entries []indexEntry
+	var candidates []int
+	for i, v := range env {
+		entries = c.candidates(v.Name, entries[:0])
+
+		for start := 0; start < len(entries); {
+			gi := entries[start].group
+
+			candidates = candidates[:0]
+			end := start
+			for ; end < len(entries) && entries[end].group == gi; end++ {
+				candidates = append(candidates, entries[end].matcher)
+			}
+			start = end
+
+			// In exclusive mode, variables already claimed by an earlier group are
+			// not offered to later groups.
+			if c.exclusive && matched[i] {
+				break
+			}
+
+			if c.groups[gi].process(&states[gi], v, candidates) && matched != nil {
+				matched[i] = true
+
+				if c.exclusive {
+					result.claims[v.Name] = c.groups[gi].name
+				}
+			}
+		}
+	}
+
+	for _, gi := range c.order {
+		group := c.groups[gi]
+
+		res, err := group.result(&states[gi], states[gi].seen, 0)
+		if res.Size() > 0 {
+			result.results[group.name] = res
+		}
+
+		errors = append(errors, states[gi].errors...)
+		errors = append(errors, err...)
+	}
+
+	for _, group := range c.groups {
+		if result.Has(group.name) {
+			result.names = append(result.names, group.name)
+		}
+	}
+
+	// For each requirement flag
+	for i, req := range c.required {
+		// if the flag is true (the matching group is required)
+		if req {
+			// ensure that we have that group.  If we don't...
+			if !result.Has(c.groups[i].name) {
+				// record an error for it
+				errors = append(errors, &MissingGroupError{Group: c.groups[i].name})
+			}
+		}
+	}
+
+	if matched != nil {
+		unmatched := make([]Variable, 0, len(env))
+		for i, v := range env {
+			if !matched[i] {
+				unmatched = append(unmatched, v)
+			}
+		}
+
+		if c.typos != nil {
+			c.typos.suggest(errors, unmatched)
+		}
+
+		c.checkNamespaces(result, unmatched, &errors)
+	}
+
+	// If we had any errors, then set them on the result.
+	if len(errors) > 0 {
+		result.errors = errors
+	}
+
+	return result
+}
+
+// checkNamespaces records the given unmatched variables that fall under a
+// claimed namespace on the given result, reporting them according to the
+// configured StrictMode.
+func (c *compiledEnvironmentMatcher) checkNamespaces(result *envMatchResult, unmatched []Variable, errors *[]error) {
+	for _, v := range unmatched {
+		ns, ok := claim(c.namespaces, v.Name)
+		if !ok {
+			continue
+		}
+
+		result.unmatched = append(result.unmatched, v)
+
+		err := &UnmatchedVariableError{
+			Variable:  v.Name,
+			Namespace: ns.prefix,
+			Group:     ns.group,
+			Origin:    v.Origin,
+		}
+
+		switch c.strict {
+		case StrictWarn:
+			result.warnings = append(result.warnings, err)
+		case StrictError:
+			*errors = append(*errors, err)
+		}
+	}
+}
+
+// candidates appends the KeyMatchers that may match the given variable name to
+// the given buffer, ordered by group and then by their position within their
+// group.
+func (c *compiledEnvironmentMatcher) candidates(name string, buf []indexEntry) []indexEntry {
+	if c.index != nil {
+		return c.index.lookup(name, buf)
+	}
+
+	for _, gi := range c.order {
+		for _, mi := range c.groups[gi].all {
+			buf = append(buf, indexEntry{gi, mi})
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,72 @@
+package wenv_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestCompiledEnvironmentMatcher(t *testing.T) {
+	Convey("compiled environment matcher", t, func() {
+		group := wenv.NewMatchGroup("plugin").
+			AddMatcher(wenv.NewPrefixMatcher("name", "PLUGIN_NAME_"), true).
+			AddMatcher(wenv.NewPrefixMatcher("path", "PLUGIN_PATH_"), true)
+
+		builder := wenv.NewEnvironmentMatcher().AddGroup(group, true)
+		matcher, err := builder.Compile()
+		So(err, ShouldBeNil)
+
+		environ := map[string]string{
+			"PLUGIN_NAME_ORANGE": "My Orange Plugin",
+			"PLUGIN_PATH_ORANGE": "/opt/app/plugins/orange",
+			"PLUGIN_NAME_PURPLE": "My Purple Plugin",
+			"PLUGIN_PATH_PURPLE": "/opt/app/plugins/purple",
+		}
+
+		Convey("may be used more than once", func() {
+			for i := 0; i < 3; i++ {
+				res := matcher.ParseEnv(environ)
+
+				So(res.Errors(), ShouldBeNil)
+				So(res.Get("plugin").Size(), ShouldEqual, 2)
+			}
+		})
+
+		Convey("leaves the builder reusable", func() {
+			So(builder.ParseEnv(environ).Get("plugin").Size(), ShouldEqual, 2)
+			So(builder.ParseEnv(environ).Get("plugin").Size(), ShouldEqual, 2)
+		})
+
+		Convey("is not affected by later changes to the builder", func() {
+			group.AddMatcher(wenv.NewPrefixMatcher("version", "PLUGIN_VERSION_"), true)
+
+			res := matcher.ParseEnv(environ)
+			So(res.Errors(), ShouldBeNil)
+
+			res = builder.ParseEnv(environ)
+			So(res.Errors().Size(), ShouldEqual, 2)
+		})
+
+		Convey("may be used concurrently", func() {
+			wg := new(sync.WaitGroup)
+			sizes := make([]int, 16)
+
+			for i := range sizes {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					sizes[i] = matcher.ParseEnv(environ).Get("plugin").Size()
+				}(i)
+			}
+
+			wg.Wait()
+
+			for _, size := range sizes {
+				So(size, ShouldEqual, 2)
+			}
+		})
+	})
+}
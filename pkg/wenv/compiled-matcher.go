@@ -0,0 +1,43 @@
+package wenv
+
+// A CompiledEnvironmentMatcher is an immutable EnvironmentMatcher that may be
+// used to parse any number of environments.
+//
+// CompiledEnvironmentMatcher instances are safe for concurrent use by multiple
+// goroutines.
+//
+// Example:
+//   matcher, err := NewEnvironmentMatcher().
+//     AddGroup(NewMatchGroup("plugins").
+//       AddMatcher(NewPrefixMatcher("name", "PLUGIN_NAME_"), true).
+//       AddMatcher(NewPrefixMatcher("path", "PLUGIN_PATH_"), true),
+//       false).
+//     Compile()
+//   if err != nil {
+//     panic(err)
+//   }
+//
+//   first := matcher.ParseEnv(SplitEnvironment(os.Environ()))
+//   second := matcher.ParseEnv(SplitEnvironment(os.Environ()))
+type CompiledEnvironmentMatcher interface {
+	// ParseEnv parses the given environment map against the compiled
+	// MatchGroups.
+	ParseEnv(env map[string]string) EnvMatchResult
+
+	// Parse loads the given Sources and parses the variables they provide
+	// against the compiled MatchGroups.
+	//
+	// Sources are loaded in the order they are given, and a variable provided
+	// by a later Source replaces a variable of the same name provided by an
+	// earlier Source, exactly as if the Sources were wrapped in a single
+	// NewLayeredSource.  Errors encountered while loading a Source are included
+	// in the errors of the returned EnvMatchResult.
+	Parse(sources ...Source) EnvMatchResult
+
+	// Warnings returns the problems found while compiling the matcher that do
+	// not prevent it from being used, such as *OverlapError values.  These are
+	// also included in the warnings of every EnvMatchResult.
+	//
+	// If there were no such problems, this method will return nil.
+	Warnings() MatcherErrors
+}
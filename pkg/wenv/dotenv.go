@@ -0,0 +1,362 @@
+package wenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseDotenv parses the dotenv formatted content of the given reader into a
+// map of variable names to values, in the same form as the map returned by
+// SplitEnvironment, and a map of variable names to the line numbers on which
+// they were defined.
+//
+// The following syntax is supported:
+//   # Comment lines and blank lines are ignored.
+//   NAME=value                 # Unquoted values are trimmed, and may be
+//                              # followed by a comment.
+//   export NAME=value          # An optional "export" prefix is ignored.
+//   NAME='literal ${value}'    # Single quoted values are used as-is.
+//   NAME="line 1\nline 2"      # Double quoted values support the escapes \n,
+//                              # \r, \t, \", \\ and \$.
+//   NAME="multiple
+//   lines"                     # Quoted values may span multiple lines.
+//   NAME=${OTHER}/path         # ${VAR} references are replaced in unquoted
+//                              # and double quoted values.
+//
+// ${VAR} references are resolved against the variables defined earlier in the
+// same content, then against the environment of the current process.  Unknown
+// variables are replaced with an empty string.
+//
+// If a variable is defined more than once, the last definition wins.
+func ParseDotenv(reader io.Reader) (env map[string]string, lines map[string]int, err error) {
+	entries, err := parseDotenv(reader, os.LookupEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env = make(map[string]string, len(entries))
+	lines = make(map[string]int, len(entries))
+
+	for _, entry := range entries {
+		env[entry.name] = entry.value
+		lines[entry.name] = entry.line
+	}
+
+	return
+}
+
+type dotenvEntry struct {
+	name  string
+	value string
+	line  int
+}
+
+// parseDotenv parses the dotenv formatted content of the given reader,
+// resolving ${VAR} references that are not defined in the content itself using
+// the given lookup function.
+func parseDotenv(reader io.Reader, lookup func(string) (string, bool)) ([]dotenvEntry, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotenvParser{
+		src:    string(data),
+		line:   1,
+		lookup: lookup,
+		values: make(map[string]string, 16),
+	}
+
+	return p.parse()
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+
+	lookup func(string) (string, bool)
+	values map[string]string
+}
+
+func (p *dotenvParser) parse() ([]dotenvEntry, error) {
+	out := make([]dotenvEntry, 0, 16)
+
+	for {
+		p.skipBlankLines()
+		if p.eof() {
+			return out, nil
+		}
+
+		line := p.line
+
+		name, err := p.readName()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected '=' after variable name %s", name)
+		}
+		p.pos++
+		p.skipSpaces()
+
+		value, err := p.readValue()
+		if err != nil {
+			return nil, err
+		}
+
+		p.values[name] = value
+		out = append(out, dotenvEntry{name, value, line})
+	}
+}
+
+// skipBlankLines skips over any whitespace, blank lines, and comment lines.
+func (p *dotenvParser) skipBlankLines() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			p.skipToEndOfLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipToEndOfLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// readName reads a variable name, skipping over a leading "export" keyword if
+// present.
+func (p *dotenvParser) readName() (string, error) {
+	name := p.readIdentifier()
+
+	if name == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		name = p.readIdentifier()
+	}
+
+	if name == "" {
+		return "", p.errorf("expected a variable name")
+	}
+
+	return name, nil
+}
+
+func (p *dotenvParser) readIdentifier() string {
+	start := p.pos
+
+	for !p.eof() && isDotenvNameChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+
+	return p.src[start:p.pos]
+}
+
+func (p *dotenvParser) readValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+
+	switch p.peek() {
+	case '\'':
+		return p.readSingleQuoted()
+	case '"':
+		return p.readDoubleQuoted()
+	default:
+		return p.readUnquoted()
+	}
+}
+
+func (p *dotenvParser) readUnquoted() (string, error) {
+	start := p.pos
+
+	for !p.eof() && p.peek() != '\n' {
+		// A '#' only starts a comment if it follows whitespace.
+		if p.peek() == '#' && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+
+	raw := strings.TrimRight(p.src[start:p.pos], " \t\r")
+	p.skipToEndOfLine()
+
+	return p.interpolate(raw)
+}
+
+func (p *dotenvParser) readSingleQuoted() (string, error) {
+	line := p.line
+	p.pos++
+	start := p.pos
+
+	for !p.eof() && p.peek() != '\'' {
+		if p.peek() == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+
+	if p.eof() {
+		return "", fmt.Errorf("line %d: unterminated single quoted value", line)
+	}
+
+	value := p.src[start:p.pos]
+	p.pos++
+
+	return value, p.endQuotedValue()
+}
+
+func (p *dotenvParser) readDoubleQuoted() (string, error) {
+	line := p.line
+	sb := new(strings.Builder)
+	p.pos++
+
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("line %d: unterminated double quoted value", line)
+		}
+
+		c := p.peek()
+		p.pos++
+
+		switch c {
+		case '"':
+			return sb.String(), p.endQuotedValue()
+
+		case '\n':
+			p.line++
+			sb.WriteByte(c)
+
+		case '\\':
+			if p.eof() {
+				continue
+			}
+
+			switch e := p.peek(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(e)
+			default:
+				sb.WriteByte('\\')
+				continue
+			}
+			p.pos++
+
+		case '$':
+			if p.eof() || p.peek() != '{' {
+				sb.WriteByte(c)
+				continue
+			}
+
+			end := strings.IndexAny(p.src[p.pos:], "}\"\n")
+			if end == -1 || p.src[p.pos+end] != '}' {
+				return "", p.errorf("unterminated variable reference")
+			}
+
+			sb.WriteString(p.resolve(p.src[p.pos+1 : p.pos+end]))
+			p.pos += end + 1
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+// endQuotedValue ensures that nothing but whitespace or a comment follows a
+// closing quote on the same line.
+func (p *dotenvParser) endQuotedValue() error {
+	p.skipSpaces()
+
+	if !p.eof() && p.peek() != '\n' && p.peek() != '\r' && p.peek() != '#' {
+		return p.errorf("unexpected character %q after quoted value", p.peek())
+	}
+
+	p.skipToEndOfLine()
+	return nil
+}
+
+// interpolate replaces ${VAR} references in the given unquoted value.
+func (p *dotenvParser) interpolate(raw string) (string, error) {
+	if !strings.Contains(raw, "${") {
+		return raw, nil
+	}
+
+	sb := new(strings.Builder)
+
+	for {
+		start := strings.Index(raw, "${")
+		if start == -1 {
+			sb.WriteString(raw)
+			return sb.String(), nil
+		}
+
+		end := strings.IndexByte(raw[start:], '}')
+		if end == -1 {
+			return "", p.errorf("unterminated variable reference")
+		}
+
+		sb.WriteString(raw[:start])
+		sb.WriteString(p.resolve(raw[start+2 : start+end]))
+		raw = raw[start+end+1:]
+	}
+}
+
+func (p *dotenvParser) resolve(name string) string {
+	if value, ok := p.values[name]; ok {
+		return value
+	}
+
+	if p.lookup != nil {
+		if value, ok := p.lookup(name); ok {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func isDotenvNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c == '.', c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package wenv_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestParseDotenv(t *testing.T) {
+	Convey("ParseDotenv", t, func() {
+		Convey("with valid content", func() {
+			t.Setenv("WENV_DOTENV_TEST_HOME", "/home/test")
+
+			env, lines, err := wenv.ParseDotenv(strings.NewReader(`# A comment
+PLAIN=value
+SPACED = spaced value   # trailing comment
+HASH=no#comment
+export EXPORTED=yes
+export=not a prefix
+EMPTY=
+SINGLE='literal ${PLAIN} \n'
+DOUBLE="tab\there \"quoted\" \$PLAIN \\"
+MULTI="line 1
+line 2"
+MULTI_SINGLE='a
+b' # comment
+INTERP=${PLAIN}/${WENV_DOTENV_TEST_HOME}/${WENV_DOTENV_TEST_MISSING}
+DOUBLE_INTERP="${SPACED}!"
+dotted.name=dots
+PLAIN=redefined
+`))
+
+			So(err, ShouldBeNil)
+			So(env, ShouldResemble, map[string]string{
+				"PLAIN":         "redefined",
+				"SPACED":        "spaced value",
+				"HASH":          "no#comment",
+				"EXPORTED":      "yes",
+				"export":        "not a prefix",
+				"EMPTY":         "",
+				"SINGLE":        `literal ${PLAIN} \n`,
+				"DOUBLE":        "tab\there \"quoted\" $PLAIN \\",
+				"MULTI":         "line 1\nline 2",
+				"MULTI_SINGLE":  "a\nb",
+				"INTERP":        "value//home/test/",
+				"DOUBLE_INTERP": "spaced value!",
+				"dotted.name":   "dots",
+			})
+
+			So(lines["SPACED"], ShouldEqual, 3)
+			So(lines["MULTI"], ShouldEqual, 10)
+			So(lines["MULTI_SINGLE"], ShouldEqual, 12)
+			So(lines["INTERP"], ShouldEqual, 14)
+			So(lines["PLAIN"], ShouldEqual, 17)
+		})
+
+		Convey("with CRLF line endings", func() {
+			env, _, err := wenv.ParseDotenv(strings.NewReader("A=1\r\nB=\"2\"\r\n"))
+
+			So(err, ShouldBeNil)
+			So(env, ShouldResemble, map[string]string{"A": "1", "B": "2"})
+		})
+
+		Convey("with invalid content", func() {
+			cases := map[string]string{
+				"NO_EQUALS":            "line 1: expected '=' after variable name NO_EQUALS",
+				"=value":               "line 1: expected a variable name",
+				"A=1\nB='unterminated": "line 2: unterminated single quoted value",
+				"A=\"unterminated\n\n": "line 1: unterminated double quoted value",
+				"A=\"value\" junk":     "line 1: unexpected character 'j' after quoted value",
+				"A=${UNTERMINATED":     "line 1: unterminated variable reference",
+				"A=\"${UNTERMINATED\"": "line 1: unterminated variable reference",
+			}
+
+			for content, message := range cases {
+				_, _, err := wenv.ParseDotenv(strings.NewReader(content))
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, message)
+			}
+		})
+	})
+}
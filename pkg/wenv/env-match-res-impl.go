@@ -1,8 +1,23 @@
 package wenv
 
+import "iter"
+
 type envMatchResult struct {
-	results map[string]MatchGroupResults
-	errors  []error
+	results   map[string]MatchGroupResults
+	names     []string
+	errors    []error
+	warnings  []error
+	unmatched []Variable
+	claims    map[string]string
+}
+
+// newErrorResult returns an empty envMatchResult containing only the given
+// errors.
+func newErrorResult(errors []error) *envMatchResult {
+	return &envMatchResult{
+		results: make(map[string]MatchGroupResults),
+		errors:  errors,
+	}
 }
 
 func (e *envMatchResult) Size() int {
@@ -22,6 +37,32 @@ func (e *envMatchResult) Get(groupName string) MatchGroupResults {
 	}
 }
 
+func (e *envMatchResult) Names() []string {
+	return e.names
+}
+
+func (e *envMatchResult) Groups() iter.Seq2[string, MatchGroupResults] {
+	return func(yield func(string, MatchGroupResults) bool) {
+		for _, name := range e.names {
+			if !yield(name, e.results[name]) {
+				return
+			}
+		}
+	}
+}
+
 func (e *envMatchResult) Errors() MatcherErrors {
 	return e.errors
 }
+
+func (e *envMatchResult) Warnings() MatcherErrors {
+	return e.warnings
+}
+
+func (e *envMatchResult) Unmatched() []Variable {
+	return e.unmatched
+}
+
+func (e *envMatchResult) Claims() map[string]string {
+	return e.claims
+}
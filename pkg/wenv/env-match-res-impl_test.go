@@ -1,6 +1,7 @@
 package wenv_test
 
 import (
+	"regexp"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -153,7 +154,7 @@ func TestEnvironmentMatcher(t *testing.T) {
 			So(envResult.Errors(), ShouldNotBeNil)
 			So(envResult.Errors().Size(), ShouldEqual, 1)
 
-			So(envResult.Errors().Get(0).Error(), ShouldEqual, "match group db (keys: FOO) does not have a match for required key user")
+			So(envResult.Errors().Get(0).Error(), ShouldEqual, "match group db (keys: FOO) does not have a match for required key user: expected variable DB_USER_FOO")
 
 			dbResults := envResult.Get("db")
 
@@ -190,3 +191,81 @@ func TestEnvironmentMatcher(t *testing.T) {
 		})
 	})
 }
+
+func TestEnvironmentMatcherNamedKeys(t *testing.T) {
+	Convey("environment matcher with named keys", t, func() {
+		envResult := wenv.NewEnvironmentMatcher().
+			AddGroup(wenv.NewMatchGroup("replicas").
+				AddMatcher(wenv.NewPatternMatcher("host", "DB_{instance}_REPLICA_{replica}_HOST"), true).
+				AddMatcher(wenv.NewPatternMatcher("port", "DB_{instance}_REPLICA_{replica}_PORT"), false),
+				true,
+			).
+			AddGroup(wenv.NewMatchGroup("plain").
+				AddMatcher(wenv.NewPrefixMatcher("name", "PLAIN_"), true),
+				true,
+			).
+			ParseEnv(map[string]string{
+				"DB_MAIN_REPLICA_A_HOST": "replica-a",
+				"DB_MAIN_REPLICA_A_PORT": "1234",
+				"PLAIN_FOO":              "foo",
+			})
+
+		So(envResult.Errors(), ShouldBeNil)
+
+		res := envResult.Get("replicas").Get(0)
+		So(res.Size(), ShouldEqual, 2)
+		So(res.Keys(), ShouldResemble, []string{"MAIN", "A"})
+		So(res.Key("instance"), ShouldEqual, "MAIN")
+		So(res.Key("replica"), ShouldEqual, "A")
+		So(res.Key("missing"), ShouldEqual, "")
+		So(res.KeyMap(), ShouldResemble, map[string]string{"instance": "MAIN", "replica": "A"})
+
+		res = envResult.Get("plain").Get(0)
+		So(res.Key("name"), ShouldEqual, "")
+		So(res.KeyMap(), ShouldBeNil)
+	})
+}
+
+func TestEnvironmentMatcherKeyNameValidation(t *testing.T) {
+	Convey("environment matcher key name validation", t, func() {
+		Convey("with agreeing key names", func() {
+			matcher, err := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("tenants").
+					AddMatcher(wenv.NewRegexMatcher("url", regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_REGION_(?P<region>\w+)_URL$`)), true).
+					AddMatcher(wenv.NewPatternMatcher("token", "TENANT_{tenant}_REGION_{region}_TOKEN"), true).
+					AddMatcher(wenv.NewSuffixMatcher("other", "_OTHER"), false),
+					true,
+				).
+				Compile()
+
+			So(err, ShouldBeNil)
+
+			res := matcher.ParseEnv(map[string]string{
+				"TENANT_FOO_REGION_EU_URL":   "https://foo.example.com",
+				"TENANT_FOO_REGION_EU_TOKEN": "secret",
+			})
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("tenants").Get(0).KeyMap(), ShouldResemble, map[string]string{"tenant": "FOO", "region": "EU"})
+		})
+
+		Convey("with conflicting key names", func() {
+			builder := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("tenants").
+					AddMatcher(wenv.NewRegexMatcher("url", regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_REGION_(?P<region>\w+)_URL$`)), true).
+					AddMatcher(wenv.NewPatternMatcher("token", "TENANT_{region}_REGION_{tenant}_TOKEN"), true),
+					true,
+				)
+
+			matcher, err := builder.Compile()
+
+			So(matcher, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.(wenv.MatcherErrors).Get(0).Error(), ShouldEqual, "match group tenants: key matcher token yields key names [region tenant] but key matcher url yields key names [tenant region]")
+
+			res := builder.ParseEnv(map[string]string{})
+			So(res.Size(), ShouldEqual, 0)
+			So(res.Errors().Size(), ShouldEqual, 1)
+		})
+	})
+}
@@ -1,8 +1,31 @@
 package wenv
 
+import (
+	"encoding/json"
+	"io"
+	"iter"
+)
+
 // EnvMatchResult contains the results of the environment matching.  The result
 // is a map of group names to the results for the named MatchGroup.
+//
+// An EnvMatchResult may be serialized with json.Marshal, WriteYAML or WriteTOML
+// into a document mapping each group name to a list of its instances:
+//   {
+//     "db": [
+//       {
+//         "keys": ["FOO"],
+//         "values": {"host": "localhost", "password": "[REDACTED]"},
+//         "raw": {"host": "DB_FOO_HOST", "password": "DB_FOO_PASSWORD"}
+//       }
+//     ]
+//   }
+//
+// The values of KeyMatchers marked as secret (see MatchGroup.SetSecret) are
+// replaced with RedactedValue.  Child group results (see MatchGroup.AddChild)
+// are nested under a "children" entry on each instance.
 type EnvMatchResult interface {
+	json.Marshaler
 
 	// Size returns the count of MatchGroupResults instances that are in this
 	// EnvMatchResult instance.
@@ -21,10 +44,56 @@ type EnvMatchResult interface {
 	// guaranteed to have at least one hit.
 	Get(groupName string) MatchGroupResults
 
+	// Names returns the names of the MatchGroups that have at least one hit in
+	// this EnvMatchResult, in the order the MatchGroups were added to the
+	// EnvironmentMatcher.
+	Names() []string
+
+	// Groups returns an iterator over the MatchGroups that have at least one hit
+	// in this EnvMatchResult, yielding the name and results of each in the same
+	// order as Names.
+	//
+	// Example:
+	//   for name, results := range result.Groups() {
+	//     fmt.Println(name, results.Size())
+	//   }
+	Groups() iter.Seq2[string, MatchGroupResults]
+
 	// Errors returns the errors that were encountered while attempting to parse
 	// and match the environment variables.  These errors will be for variables
 	// or groups that were required but were not present in the environment.
 	//
 	// If the environment parsing had no errors, this method will return nil.
 	Errors() MatcherErrors
+
+	// Warnings returns the non-fatal problems that were encountered while
+	// matching the environment variables, such as unmatched variables reported
+	// by StrictWarn mode.
+	//
+	// If the environment parsing had no warnings, this method will return nil.
+	Warnings() MatcherErrors
+
+	// Unmatched returns the environment variables that fall under a claimed
+	// namespace but were not matched by any KeyMatcher, in the order they were
+	// parsed.
+	//
+	// Unmatched variables are listed regardless of the configured StrictMode.
+	// If there were no unmatched variables, this method will return nil.
+	Unmatched() []Variable
+
+	// Claims returns a map of environment variable names to the name of the
+	// MatchGroup that consumed them.
+	//
+	// Claims are only recorded when the EnvironmentMatcher is in exclusive mode
+	// (see EnvironmentMatcher.SetExclusive); otherwise this method will return
+	// nil.
+	Claims() map[string]string
+
+	// WriteYAML writes this EnvMatchResult to the given writer as a YAML
+	// document.
+	WriteYAML(w io.Writer) error
+
+	// WriteTOML writes this EnvMatchResult to the given writer as a TOML
+	// document.
+	WriteTOML(w io.Writer) error
 }
@@ -1,6 +1,6 @@
 package wenv
 
-import "fmt"
+import "sort"
 
 // NewEnvironmentMatcher returns a new EnvironmentMatcher instance.
 //
@@ -25,6 +25,13 @@ func NewEnvironmentMatcher() EnvironmentMatcher {
 type environmentMatcher struct {
 	groups   []MatchGroup
 	required []bool
+
+	// typos holds the typo detection options, if enabled.
+	typos *TypoOptions
+
+	namespaces []string
+	strict     StrictMode
+	exclusive  bool
 }
 
 func (e *environmentMatcher) AddGroup(group MatchGroup, required bool) EnvironmentMatcher {
@@ -33,48 +40,99 @@ func (e *environmentMatcher) AddGroup(group MatchGroup, required bool) Environme
 	return e
 }
 
-func (e *environmentMatcher) ParseEnv(env map[string]string) EnvMatchResult {
-	result := &envMatchResult{
-		results: make(map[string]MatchGroupResults),
+func (e *environmentMatcher) SetTypoDetection(options TypoOptions) EnvironmentMatcher {
+	e.typos = &options
+	return e
+}
+
+func (e *environmentMatcher) AddNamespace(prefix string) EnvironmentMatcher {
+	e.namespaces = append(e.namespaces, prefix)
+	return e
+}
+
+func (e *environmentMatcher) SetStrictMode(mode StrictMode) EnvironmentMatcher {
+	e.strict = mode
+	return e
+}
+
+func (e *environmentMatcher) SetExclusive(exclusive bool) EnvironmentMatcher {
+	e.exclusive = exclusive
+	return e
+}
+
+func (e *environmentMatcher) Compile() (CompiledEnvironmentMatcher, error) {
+	if out, errs := e.compile(); errs != nil {
+		return nil, errs
+	} else {
+		return out, nil
 	}
+}
 
-	// Make an error slice to contain any errors we encounter.
-	// We don't set this on the result right now so we don't waste mem on an empty
-	// slice if there are no errors.  In the case that there _are_ errors, then we
-	// will set this error list on the result.  In the case that there are not any
-	// errors, then the result's errors ref will remain nil.
-	errors := make([]error, 0, 8)
+func (e *environmentMatcher) ParseEnv(env map[string]string) EnvMatchResult {
+	if matcher, errs := e.compile(); errs != nil {
+		return newErrorResult(errs)
+	} else {
+		return matcher.ParseEnv(env)
+	}
+}
 
-	for _, group := range e.groups {
-		for k, v := range env {
-			group.process(k, v)
-		}
+func (e *environmentMatcher) Parse(sources ...Source) EnvMatchResult {
+	if matcher, errs := e.compile(); errs != nil {
+		return newErrorResult(errs)
+	} else {
+		return matcher.Parse(sources...)
+	}
+}
 
-		res, err := group.result()
-		if res.Size() > 0 {
-			result.results[group.Name()] = res
-		}
+func (e *environmentMatcher) compile() (*compiledEnvironmentMatcher, MatcherErrors) {
+	out := &compiledEnvironmentMatcher{
+		groups:    make([]*compiledMatchGroup, len(e.groups)),
+		required:  make([]bool, len(e.required)),
+		strict:    e.strict,
+		exclusive: e.exclusive,
+	}
 
-		errors = append(errors, err...)
-		group.release()
+	for _, prefix := range e.namespaces {
+		out.namespaces = append(out.namespaces, namespace{prefix: prefix})
 	}
 
-	// For each requirement flag
-	for i, req := range e.required {
-		// if the flag is true (the matching group is required)
-		if req {
-			// ensure that we have that group.  If we don't...
-			if !result.Has(e.groups[i].Name()) {
-				// record an error for it
-				errors = append(errors, fmt.Errorf("no environment matches found for environment group %s", e.groups[i].Name()))
+	var errors MatcherErrors
+
+	for i, group := range e.groups {
+		if cg, err := group.compile(); err != nil {
+			errors = append(errors, err)
+		} else {
+			out.groups[i] = cg
+			out.warnings = append(out.warnings, cg.warnings...)
+
+			for _, prefix := range cg.namespaces {
+				out.namespaces = append(out.namespaces, namespace{prefix, cg.name})
 			}
 		}
 	}
 
-	// If we had any errors, then set them on the result.
-	if len(errors) > 0 {
-		result.errors = errors
+	if errors.HasErrors() {
+		return nil, errors
+	}
+
+	copy(out.required, e.required)
+
+	// Order the groups by priority, keeping the registration order of groups
+	// with the same priority.
+	out.order = make([]int, len(out.groups))
+	for i := range out.order {
+		out.order[i] = i
+	}
+	sort.SliceStable(out.order, func(i, j int) bool {
+		return out.groups[out.order[i]].priority > out.groups[out.order[j]].priority
+	})
+
+	out.index = newMatcherIndex(out.groups, out.order)
+
+	if e.typos != nil {
+		typos := *e.typos
+		out.typos = &typos
 	}
 
-	return result
+	return out, nil
 }
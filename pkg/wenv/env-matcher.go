@@ -11,7 +11,108 @@ type EnvironmentMatcher interface {
 	// ParseEnv will contain an error for the MatchGroup.
 	AddGroup(group MatchGroup, required bool) EnvironmentMatcher
 
+	// SetTypoDetection enables typo detection using the given options.
+	//
+	// With typo detection enabled, environment variables that were not matched
+	// by any MatchGroup are compared against the variable names expected for
+	// missing required keys.  Any that are within the configured edit distance
+	// of an expected name are listed as "did you mean" suggestions on the
+	// corresponding *MissingKeyError.
+	//
+	// Example:
+	//   // With DB_FOO_ADRESS set instead of DB_FOO_ADDRESS, the result errors
+	//   // will contain:
+	//   //   match group db (keys: FOO) does not have a match for required key
+	//   //   address: expected variable DB_FOO_ADDRESS (did you mean DB_FOO_ADRESS?)
+	//   NewEnvironmentMatcher().
+	//     AddGroup(NewMatchGroup("db").
+	//       AddMatcher(NewWrappedMatcher("host", "DB_", "_HOST"), true).
+	//       AddMatcher(NewWrappedMatcher("address", "DB_", "_ADDRESS"), true),
+	//       true).
+	//     SetTypoDetection(TypoOptions{}).
+	//     ParseEnv(SplitEnvironment(os.Environ()))
+	SetTypoDetection(options TypoOptions) EnvironmentMatcher
+
+	// AddNamespace claims the given variable name prefix for this
+	// EnvironmentMatcher.
+	//
+	// Environment variables whose names start with a claimed prefix are expected
+	// to be matched by a KeyMatcher in one of the configured MatchGroups.  Any
+	// that are not are listed by EnvMatchResult.Unmatched, and are reported
+	// according to the configured StrictMode.
+	//
+	// Namespaces may also be claimed by individual MatchGroups; see
+	// MatchGroup.AddNamespace.
+	AddNamespace(prefix string) EnvironmentMatcher
+
+	// SetStrictMode sets how unmatched environment variables in a claimed
+	// namespace are reported.  Defaults to StrictOff.
+	//
+	// Example:
+	//   result := NewEnvironmentMatcher().
+	//     AddGroup(NewMatchGroup("db").
+	//       AddNamespace("DB_").
+	//       AddMatcher(NewWrappedMatcher("host", "DB_", "_HOST"), true).
+	//       AddMatcher(NewWrappedMatcher("port", "DB_", "_PORT"), true),
+	//       true).
+	//     SetStrictMode(StrictError).
+	//     ParseEnv(SplitEnvironment(os.Environ()))
+	//
+	//   // DB_FOO_HSOT would be reported as an *UnmatchedVariableError
+	SetStrictMode(mode StrictMode) EnvironmentMatcher
+
+	// SetExclusive sets whether environment variables are consumed exclusively
+	// by the first MatchGroup that matches them.  Defaults to false, meaning
+	// every MatchGroup is given every environment variable.
+	//
+	// In exclusive mode, MatchGroups are given the environment variables in
+	// order of priority (see MatchGroup.SetPriority), and a variable matched by
+	// one MatchGroup is not given to any later MatchGroup.  The MatchGroup that
+	// claimed each variable is listed by EnvMatchResult.Claims.
+	//
+	// Example:
+	//   // CACHE_REDIS_MAIN_HOST is only matched by the redis group.
+	//   NewEnvironmentMatcher().
+	//     AddGroup(NewMatchGroup("cache").
+	//       AddMatcher(NewWrappedMatcher("host", "CACHE_", "_HOST"), true),
+	//       false).
+	//     AddGroup(NewMatchGroup("redis").
+	//       SetPriority(1).
+	//       AddMatcher(NewWrappedMatcher("host", "CACHE_REDIS_", "_HOST"), true),
+	//       false).
+	//     SetExclusive(true).
+	//     ParseEnv(SplitEnvironment(os.Environ()))
+	SetExclusive(exclusive bool) EnvironmentMatcher
+
+	// Compile takes a snapshot of the MatchGroups currently configured on this
+	// EnvironmentMatcher and returns an immutable CompiledEnvironmentMatcher
+	// built from them.
+	//
+	// Changes made to this EnvironmentMatcher or its MatchGroups after Compile
+	// has been called will not affect the returned CompiledEnvironmentMatcher.
+	//
+	// If any of the configured MatchGroups are not valid, Compile returns a nil
+	// CompiledEnvironmentMatcher and a MatcherErrors value describing the
+	// problems, such as *KeyNameError values.
+	Compile() (CompiledEnvironmentMatcher, error)
+
 	// ParseEnv parses the given environment map against the configured
 	// MatchGroups.
+	//
+	// This is a shortcut for calling Compile and then ParseEnv on the result.
+	// Callers that expect to parse more than one environment should call Compile
+	// once and reuse the result.
+	//
+	// If the configured MatchGroups are not valid, the returned EnvMatchResult
+	// will contain no results and its errors will be the errors returned by
+	// Compile.
 	ParseEnv(env map[string]string) EnvMatchResult
+
+	// Parse loads the given Sources and parses the variables they provide
+	// against the configured MatchGroups.
+	//
+	// See CompiledEnvironmentMatcher.Parse for details on how the Sources are
+	// combined.  Like ParseEnv, this is a shortcut for calling Compile and then
+	// Parse on the result.
+	Parse(sources ...Source) EnvMatchResult
 }
@@ -0,0 +1,398 @@
+package wenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Error kinds used in ErrorReport entries.
+const (
+	ErrorKindMissingGroup = "missing_group"
+	ErrorKindMissingKey   = "missing_key"
+	ErrorKindConversion   = "conversion"
+	ErrorKindFile         = "file"
+	ErrorKindSource       = "source"
+	ErrorKindInvalidGroup = "invalid_group"
+	ErrorKindUnmatched    = "unmatched"
+	ErrorKindAmbiguous    = "ambiguous"
+	ErrorKindOverlap      = "overlap"
+	ErrorKindIndex        = "index"
+	ErrorKindOrphan       = "orphan"
+	ErrorKindOther        = "other"
+)
+
+// ErrorReport is a machine-readable description of a single error contained in
+// a MatcherErrors list.
+type ErrorReport struct {
+	// Kind is the kind of error, one of the ErrorKind constants.
+	Kind string `json:"kind"`
+
+	// Group is the name of the MatchGroup the error relates to, if any.
+	Group string `json:"group,omitempty"`
+
+	// Keys are the keys of the MatchGroupResult the error relates to, if any.
+	Keys []string `json:"keys,omitempty"`
+
+	// Matcher is the name of the KeyMatcher the error relates to, if any.
+	Matcher string `json:"matcher,omitempty"`
+
+	// Variable is the name of the environment variable the error relates to, if
+	// known.
+	Variable string `json:"variable,omitempty"`
+
+	// Message is the error message.
+	Message string `json:"message"`
+
+	// Hint is a human readable suggestion for fixing the error, if any.
+	Hint string `json:"hint,omitempty"`
+}
+
+// reportable is implemented by the errors in this package that are able to
+// describe themselves as an ErrorReport.
+type reportable interface {
+	report() ErrorReport
+}
+
+func (m *MissingGroupError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindMissingGroup,
+		Group:   m.Group,
+		Keys:    m.Keys,
+		Message: m.Error(),
+		Hint:    fmt.Sprintf("set the environment variables for at least one instance of group %s", m.Group),
+	}
+}
+
+func (m *MissingKeyError) report() ErrorReport {
+	out := ErrorReport{
+		Kind:     ErrorKindMissingKey,
+		Group:    m.Group,
+		Keys:     m.Keys,
+		Matcher:  m.Matcher,
+		Variable: m.Variable,
+		Message:  m.Error(),
+	}
+
+	if m.Variable != "" {
+		out.Hint = fmt.Sprintf("set the environment variable %s", m.Variable)
+		if len(m.Suggestions) > 0 {
+			out.Hint += fmt.Sprintf(" or rename %s", strings.Join(m.Suggestions, ", "))
+		}
+	} else {
+		out.Hint = fmt.Sprintf("set the environment variable for key %s", m.Matcher)
+	}
+
+	return out
+}
+
+func (c *ConversionError) report() ErrorReport {
+	return ErrorReport{
+		Kind:     ErrorKindConversion,
+		Group:    c.Group,
+		Keys:     c.Keys,
+		Matcher:  c.Matcher,
+		Variable: c.Variable,
+		Message:  c.Error(),
+		Hint:     fmt.Sprintf("set the environment variable to a valid %s value", c.Type),
+	}
+}
+
+func (f *FileError) report() ErrorReport {
+	return ErrorReport{
+		Kind:     ErrorKindFile,
+		Group:    f.Group,
+		Keys:     f.Keys,
+		Matcher:  f.Matcher,
+		Variable: f.Variable,
+		Message:  f.Error(),
+		Hint:     fmt.Sprintf("make sure the file %s exists and is readable", f.Path),
+	}
+}
+
+func (s *SourceError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindSource,
+		Message: s.Error(),
+		Hint:    fmt.Sprintf("make sure the source %s is available", s.Source),
+	}
+}
+
+func (k *KeyNameError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindInvalidGroup,
+		Group:   k.Group,
+		Matcher: k.Matcher,
+		Message: k.Error(),
+		Hint:    "use the same key names for every named key matcher in the group",
+	}
+}
+
+func (s *SecretNameError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindInvalidGroup,
+		Group:   s.Group,
+		Matcher: s.Matcher,
+		Message: s.Error(),
+		Hint:    "mark only key matchers added to the group as secret",
+	}
+}
+
+func (u *UnmatchedVariableError) report() ErrorReport {
+	return ErrorReport{
+		Kind:     ErrorKindUnmatched,
+		Group:    u.Group,
+		Variable: u.Variable,
+		Message:  u.Error(),
+		Hint:     fmt.Sprintf("remove the environment variable %s or check its name for typos", u.Variable),
+	}
+}
+
+func (a *AmbiguousMatchError) report() ErrorReport {
+	return ErrorReport{
+		Kind:     ErrorKindAmbiguous,
+		Group:    a.Group,
+		Variable: a.Variable,
+		Message:  a.Error(),
+		Hint:     "rename the environment variable or set a different overlap policy on the group",
+	}
+}
+
+func (o *OverlapError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindOverlap,
+		Group:   o.Group,
+		Matcher: o.Matcher,
+		Message: o.Error(),
+		Hint:    "use non-overlapping prefixes and suffixes or set an overlap policy on the group",
+	}
+}
+
+func (i *IndexError) report() ErrorReport {
+	out := ErrorReport{
+		Kind:    ErrorKindIndex,
+		Group:   i.Group,
+		Keys:    i.Keys,
+		Message: i.Error(),
+	}
+
+	switch i.Problem {
+	case IndexInvalid:
+		out.Hint = "use a non-negative integer as the index"
+	case IndexDuplicate:
+		out.Hint = "remove one of the environment variables using the same index"
+	case IndexGap:
+		out.Hint = "set the environment variables for the missing indices or renumber the existing ones"
+	case IndexBelowBase:
+		out.Hint = "renumber the environment variables to start at the first index"
+	}
+
+	return out
+}
+
+func (o *OrphanError) report() ErrorReport {
+	return ErrorReport{
+		Kind:    ErrorKindOrphan,
+		Group:   o.Group,
+		Keys:    o.Keys,
+		Message: o.Error(),
+		Hint:    fmt.Sprintf("set the environment variables for the matching instance of group %s", o.Parent),
+	}
+}
+
+// Report returns an ErrorReport for each error in this MatcherErrors list.
+//
+// Errors that were not created by this package are reported with the kind
+// ErrorKindOther and only their message.
+func (m MatcherErrors) Report() []ErrorReport {
+	out := make([]ErrorReport, len(m))
+
+	for i, err := range m {
+		var r reportable
+		if errors.As(err, &r) {
+			out[i] = r.report()
+		} else {
+			out[i] = ErrorReport{Kind: ErrorKindOther, Message: err.Error()}
+		}
+	}
+
+	return out
+}
+
+// WriteJSON writes the errors in this MatcherErrors list to the given writer as
+// a JSON document of the form:
+//   {
+//     "count": 1,
+//     "errors": [
+//       {
+//         "kind": "missing_key",
+//         "group": "db",
+//         "keys": ["FOO"],
+//         "matcher": "port",
+//         "variable": "DB_FOO_PORT",
+//         "message": "match group db (keys: FOO) does not have a match for required key port: expected variable DB_FOO_PORT",
+//         "hint": "set the environment variable DB_FOO_PORT"
+//       }
+//     ]
+//   }
+func (m MatcherErrors) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(struct {
+		Count  int           `json:"count"`
+		Errors []ErrorReport `json:"errors"`
+	}{len(m), m.Report()})
+}
+
+// SARIF result levels accepted by WriteSARIF.
+const (
+	// SARIFError is the level used for the results of EnvMatchResult.Errors.
+	SARIFError = "error"
+
+	// SARIFWarning is the level used for the results of Warnings, such as
+	// EnvMatchResult.Warnings and CompiledEnvironmentMatcher.Warnings.
+	SARIFWarning = "warning"
+)
+
+// sarifVersion and sarifSchema identify the version of the SARIF format written
+// by WriteSARIF.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the errors in this MatcherErrors list to the given writer
+// as a SARIF 2.1.0 log, for consumption by tooling that understands the format.
+//
+// Each error kind is reported as a rule, and each error is reported as a result
+// whose logical location is the environment variable, if known, or the
+// MatchGroup, instance keys and KeyMatcher the error relates to.  Hints are
+// appended to the result messages.
+//
+// All results are reported at the given level, which should be SARIFError for
+// errors and SARIFWarning for warnings.
+//
+// Example:
+//   err := result.Errors().WriteSARIF(errFile, SARIFError)
+//   err = result.Warnings().WriteSARIF(warnFile, SARIFWarning)
+func (m MatcherErrors) WriteSARIF(w io.Writer, level string) error {
+	reports := m.Report()
+	run := sarifRun{
+		Tool:    sarifTool{sarifDriver{Name: "wenv", Rules: []sarifRule{}}},
+		Results: make([]sarifResult, len(reports)),
+	}
+
+	seen := make(map[string]bool, 8)
+
+	for i, r := range reports {
+		if !seen[r.Kind] {
+			seen[r.Kind] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{r.Kind})
+		}
+
+		text := r.Message
+		if r.Hint != "" {
+			text += " (" + r.Hint + ")"
+		}
+
+		run.Results[i] = sarifResult{RuleID: r.Kind, Level: level, Message: sarifMessage{text}}
+
+		if r.Variable != "" {
+			run.Results[i].Locations = []sarifLocation{{[]sarifLogicalLocation{{r.Variable, "variable"}}}}
+		} else if r.Group != "" {
+			run.Results[i].Locations = []sarifLocation{{[]sarifLogicalLocation{{r.location(), "member"}}}}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{sarifVersion, sarifSchema, []sarifRun{run}})
+}
+
+// location returns the group, keys and matcher of the report joined into a
+// single path, such as "db/FOO/port".
+func (e ErrorReport) location() string {
+	parts := []string{e.Group}
+
+	if len(e.Keys) > 0 {
+		parts = append(parts, strings.Join(e.Keys, ","))
+	}
+	if e.Matcher != "" {
+		parts = append(parts, e.Matcher)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// Detail returns a multi-line description of the errors in this list, made up
+// of the summary returned by Error followed by one indented line per error.
+//
+// Example:
+//   encountered 2 environment parsing errors:
+//     - no environment matches found for environment group cache
+//     - match group db (keys: FOO) does not have a match for required key port: expected variable DB_FOO_PORT
+func (m MatcherErrors) Detail() string {
+	sb := new(strings.Builder)
+	sb.WriteString(m.Error())
+
+	if m.IsEmpty() {
+		return sb.String()
+	}
+
+	sb.WriteByte(':')
+
+	for _, err := range m {
+		sb.WriteString("\n  - ")
+		sb.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n    "))
+	}
+
+	return sb.String()
+}
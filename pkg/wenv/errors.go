@@ -0,0 +1,583 @@
+package wenv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors identifying each kind of error reported by this package.
+//
+// Every error type defined by this package matches exactly one of these
+// sentinels when tested with errors.Is, which allows errors to be checked or
+// filtered by kind without knowing their concrete type.
+//
+// Example:
+//   if errors.Is(result.Errors(), ErrMissingKey) {
+//     // at least one required key was missing
+//   }
+var (
+	// ErrMissingGroup is matched by *MissingGroupError values.
+	ErrMissingGroup = errors.New("missing match group")
+
+	// ErrMissingKey is matched by *MissingKeyError values.
+	ErrMissingKey = errors.New("missing key")
+
+	// ErrConversion is matched by *ConversionError values.
+	ErrConversion = errors.New("conversion failed")
+
+	// ErrFile is matched by *FileError values.
+	ErrFile = errors.New("file indirection failed")
+
+	// ErrSource is matched by *SourceError values.
+	ErrSource = errors.New("source failed to load")
+
+	// ErrInvalidGroup is matched by *KeyNameError and *SecretNameError values.
+	ErrInvalidGroup = errors.New("invalid match group")
+
+	// ErrUnmatched is matched by *UnmatchedVariableError values.
+	ErrUnmatched = errors.New("unmatched variable")
+
+	// ErrAmbiguous is matched by *AmbiguousMatchError values.
+	ErrAmbiguous = errors.New("ambiguous match")
+
+	// ErrOverlap is matched by *OverlapError values.
+	ErrOverlap = errors.New("overlapping key matchers")
+
+	// ErrIndex is matched by *IndexError values.
+	ErrIndex = errors.New("invalid list index")
+
+	// ErrOrphan is matched by *OrphanError values.
+	ErrOrphan = errors.New("orphaned child instance")
+)
+
+// groupError is implemented by the errors in this package that relate to a
+// specific MatchGroup, and optionally a specific KeyMatcher within it.
+type groupError interface {
+	errorGroup() string
+	errorMatcher() string
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Missing Group Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// MissingGroupError is reported when a required MatchGroup did not match any
+// environment variables.
+type MissingGroupError struct {
+	// Group is the name of the missing MatchGroup.
+	Group string
+
+	// Keys are the keys of the parent instance missing the MatchGroup, if the
+	// MatchGroup is a child group (see MatchGroup.AddChild).
+	Keys []string
+}
+
+func (m *MissingGroupError) Error() string {
+	if m.Keys != nil {
+		return fmt.Sprintf("no environment matches found for environment group %s (parent keys: %s)", m.Group, strings.Join(m.Keys, ","))
+	}
+
+	return fmt.Sprintf("no environment matches found for environment group %s", m.Group)
+}
+
+func (m *MissingGroupError) Is(target error) bool {
+	return target == ErrMissingGroup
+}
+
+func (m *MissingGroupError) errorGroup() string   { return m.Group }
+func (m *MissingGroupError) errorMatcher() string { return "" }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Missing Key Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// MissingKeyError is reported when a MatchGroupResult does not have a match for
+// a KeyMatcher that was required, or that a value was requested from.
+type MissingKeyError struct {
+	// Group is the name of the MatchGroup the missing key belongs to.
+	Group string
+
+	// Keys are the keys of the MatchGroupResult that is missing the key.
+	Keys []string
+
+	// Matcher is the name of the KeyMatcher that did not match.
+	Matcher string
+
+	// Variable is the name of the environment variable the KeyMatcher expected
+	// to find, if the KeyMatcher is able to render it (see KeyRenderer).
+	Variable string
+
+	// Suggestions are the names of unmatched environment variables that look
+	// like misspellings of Variable.  Suggestions are only populated when typo
+	// detection is enabled (see EnvironmentMatcher.SetTypoDetection).
+	Suggestions []string
+
+	// Field is the name of the struct field that required the key, if any.
+	Field string
+
+	// Required is whether the KeyMatcher was required.
+	Required bool
+}
+
+func (m *MissingKeyError) Error() string {
+	var msg string
+	keys := strings.Join(m.Keys, ",")
+
+	switch {
+	case m.Field != "":
+		msg = fmt.Sprintf("match group %s (keys: %s) has no value for required field %s (key %s)", m.Group, keys, m.Field, m.Matcher)
+	case m.Required:
+		msg = fmt.Sprintf("match group %s (keys: %s) does not have a match for required key %s", m.Group, keys, m.Matcher)
+	default:
+		msg = fmt.Sprintf("match group %s (keys: %s) does not have a match for key %s", m.Group, keys, m.Matcher)
+	}
+
+	if m.Variable != "" {
+		msg += ": expected variable " + m.Variable
+	}
+
+	if len(m.Suggestions) > 0 {
+		msg += " (did you mean " + strings.Join(m.Suggestions, ", ") + "?)"
+	}
+
+	return msg
+}
+
+func (m *MissingKeyError) Is(target error) bool {
+	return target == ErrMissingKey
+}
+
+func (m *MissingKeyError) errorGroup() string   { return m.Group }
+func (m *MissingKeyError) errorMatcher() string { return m.Matcher }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Conversion Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// ConversionError is returned when the value of a matched environment variable
+// could not be converted to the requested type.
+type ConversionError struct {
+	// Group is the name of the MatchGroup the value belongs to.
+	Group string
+
+	// Keys are the keys of the MatchGroupResult the value belongs to.
+	Keys []string
+
+	// Matcher is the name of the KeyMatcher that matched the variable.
+	Matcher string
+
+	// Variable is the raw name of the environment variable.  If the value came
+	// from a default rather than the environment, this will be empty.
+	Variable string
+
+	// Field is the name of the struct field the value was being bound to, if
+	// any.
+	Field string
+
+	// Value is the value that could not be converted.  For Secret values, this
+	// will be empty.
+	Value string
+
+	// Secret is set when the value belongs to a secret KeyMatcher (see
+	// MatchGroup.SetSecret).  The error message then shows RedactedValue in
+	// place of the value, and Err is replaced with a generic error as parsing
+	// errors commonly repeat the value they failed to parse.
+	Secret bool
+
+	// Type is the name of the type the value was being converted to.
+	Type string
+
+	// Err is the underlying parsing error.
+	Err error
+}
+
+func (c *ConversionError) Error() string {
+	sb := new(strings.Builder)
+
+	fmt.Fprintf(sb, "match group %s (keys: %s): cannot convert ", c.Group, strings.Join(c.Keys, ","))
+
+	value := strconv.Quote(c.Value)
+	if c.Secret {
+		value = RedactedValue
+	}
+
+	if c.Variable == "" {
+		fmt.Fprintf(sb, "default value %s for key %s", value, c.Matcher)
+	} else {
+		fmt.Fprintf(sb, "%s (%s)", c.Variable, value)
+	}
+
+	fmt.Fprintf(sb, " to %s", c.Type)
+
+	if c.Field != "" {
+		fmt.Fprintf(sb, " for field %s", c.Field)
+	}
+
+	fmt.Fprintf(sb, ": %s", c.Err)
+
+	return sb.String()
+}
+
+// redact removes the value and underlying error from this error if the value
+// is secret, returning the error itself.
+func (c *ConversionError) redact() *ConversionError {
+	if c.Secret {
+		c.Value, c.Err = "", redactedError{}
+	}
+	return c
+}
+
+func (c *ConversionError) Unwrap() error {
+	return c.Err
+}
+
+func (c *ConversionError) Is(target error) bool {
+	return target == ErrConversion
+}
+
+func (c *ConversionError) errorGroup() string   { return c.Group }
+func (c *ConversionError) errorMatcher() string { return c.Matcher }
+
+// redactedError takes the place of the underlying error of a *ConversionError
+// for a secret value.
+type redactedError struct{}
+
+func (redactedError) Error() string {
+	return "value is not valid (details redacted)"
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    File Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// FileError is returned when the value for a KeyMatcher could not be read
+// through file indirection (see WithFileIndirection).
+type FileError struct {
+	// Group is the name of the MatchGroup the value belongs to.
+	Group string
+
+	// Keys are the keys of the MatchGroupResult the value belongs to.
+	Keys []string
+
+	// Matcher is the name of the KeyMatcher that matched the variable.
+	Matcher string
+
+	// Variable is the raw name of the file indirection variable.
+	Variable string
+
+	// Path is the path to the file, as given by the variable's value.
+	Path string
+
+	// Err is the error encountered while reading the file.
+	Err error
+}
+
+func (f *FileError) Error() string {
+	return fmt.Sprintf("match group %s (keys: %s): cannot read file %q given by %s for key %s: %s", f.Group, strings.Join(f.Keys, ","), f.Path, f.Variable, f.Matcher, f.Err)
+}
+
+func (f *FileError) Unwrap() error {
+	return f.Err
+}
+
+func (f *FileError) Is(target error) bool {
+	return target == ErrFile
+}
+
+func (f *FileError) errorGroup() string   { return f.Group }
+func (f *FileError) errorMatcher() string { return f.Matcher }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Source Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// SourceError is reported when a Source could not be loaded.
+type SourceError struct {
+	// Source is the name of the Source that failed to load.
+	Source string
+
+	// Err is the error returned by the Source.
+	Err error
+}
+
+func (s *SourceError) Error() string {
+	return fmt.Sprintf("failed to load environment source %s: %s", s.Source, s.Err)
+}
+
+func (s *SourceError) Unwrap() error {
+	return s.Err
+}
+
+func (s *SourceError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Key Name Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// KeyNameError is returned when compiling a MatchGroup whose NamedKeyMatchers
+// do not agree on the names of their keys.
+type KeyNameError struct {
+	// Group is the name of the invalid MatchGroup.
+	Group string
+
+	// Matcher is the name of the KeyMatcher whose key names conflict.
+	Matcher string
+
+	// KeyNames are the key names of the conflicting KeyMatcher.
+	KeyNames []string
+
+	// Expected is the name of the KeyMatcher that defined the group's key names.
+	Expected string
+
+	// ExpectedKeyNames are the key names defined by the Expected KeyMatcher.
+	ExpectedKeyNames []string
+}
+
+func (k *KeyNameError) Error() string {
+	return fmt.Sprintf("match group %s: key matcher %s yields key names %v but key matcher %s yields key names %v", k.Group, k.Matcher, k.KeyNames, k.Expected, k.ExpectedKeyNames)
+}
+
+func (k *KeyNameError) Is(target error) bool {
+	return target == ErrInvalidGroup
+}
+
+func (k *KeyNameError) errorGroup() string   { return k.Group }
+func (k *KeyNameError) errorMatcher() string { return k.Matcher }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Secret Name Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// SecretNameError is returned when compiling a MatchGroup that marks a
+// KeyMatcher as secret (see MatchGroup.SetSecret) that is not in the group.
+type SecretNameError struct {
+	// Group is the name of the invalid MatchGroup.
+	Group string
+
+	// Matcher is the unknown KeyMatcher name given to SetSecret.
+	Matcher string
+}
+
+func (s *SecretNameError) Error() string {
+	return fmt.Sprintf("match group %s: cannot mark unknown key matcher %s as secret", s.Group, s.Matcher)
+}
+
+func (s *SecretNameError) Is(target error) bool {
+	return target == ErrInvalidGroup
+}
+
+func (s *SecretNameError) errorGroup() string   { return s.Group }
+func (s *SecretNameError) errorMatcher() string { return s.Matcher }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Unmatched Variable Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// UnmatchedVariableError is reported in strict mode for an environment
+// variable that falls under a claimed namespace but was not matched by any
+// KeyMatcher.
+type UnmatchedVariableError struct {
+	// Variable is the name of the unmatched environment variable.
+	Variable string
+
+	// Namespace is the claimed namespace prefix the variable falls under.
+	Namespace string
+
+	// Group is the name of the MatchGroup that claimed the namespace.  If the
+	// namespace was claimed by the EnvironmentMatcher, this will be empty.
+	Group string
+
+	// Origin describes where the variable came from.
+	Origin Origin
+}
+
+func (u *UnmatchedVariableError) Error() string {
+	if u.Group == "" {
+		return fmt.Sprintf("environment variable %s in namespace %s was not matched by any key matcher", u.Variable, u.Namespace)
+	}
+
+	return fmt.Sprintf("environment variable %s in namespace %s of match group %s was not matched by any key matcher", u.Variable, u.Namespace, u.Group)
+}
+
+func (u *UnmatchedVariableError) Is(target error) bool {
+	return target == ErrUnmatched
+}
+
+func (u *UnmatchedVariableError) errorGroup() string   { return u.Group }
+func (u *UnmatchedVariableError) errorMatcher() string { return "" }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Ambiguous Match Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// AmbiguousMatchError is reported when an environment variable is matched by
+// more than one KeyMatcher in a MatchGroup using the OverlapReject policy.
+type AmbiguousMatchError struct {
+	// Group is the name of the MatchGroup.
+	Group string
+
+	// Variable is the name of the ambiguous environment variable.
+	Variable string
+
+	// Matchers are the names of the KeyMatchers that matched the variable, in
+	// the order they were added to the MatchGroup.
+	Matchers []string
+}
+
+func (a *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("match group %s: environment variable %s is matched by multiple key matchers: %s", a.Group, a.Variable, strings.Join(a.Matchers, ", "))
+}
+
+func (a *AmbiguousMatchError) Is(target error) bool {
+	return target == ErrAmbiguous
+}
+
+func (a *AmbiguousMatchError) errorGroup() string   { return a.Group }
+func (a *AmbiguousMatchError) errorMatcher() string { return "" }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Overlap Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// OverlapError is reported as a warning when a MatchGroup is compiled with
+// KeyMatchers that may match the same environment variables, and the group's
+// OverlapPolicy is OverlapAll or OverlapReject.
+//
+// Overlaps are only detected between the built-in prefix, suffix, wrapped and
+// pattern KeyMatchers; regex and custom KeyMatchers are never reported.
+type OverlapError struct {
+	// Group is the name of the MatchGroup.
+	Group string
+
+	// Matcher is the name of the KeyMatcher that was added to the MatchGroup
+	// later.
+	Matcher string
+
+	// Other is the name of the KeyMatcher that was added to the MatchGroup
+	// earlier.
+	Other string
+}
+
+func (o *OverlapError) Error() string {
+	return fmt.Sprintf("match group %s: key matcher %s may match the same environment variables as key matcher %s", o.Group, o.Matcher, o.Other)
+}
+
+func (o *OverlapError) Is(target error) bool {
+	return target == ErrOverlap
+}
+
+func (o *OverlapError) errorGroup() string   { return o.Group }
+func (o *OverlapError) errorMatcher() string { return o.Matcher }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Index Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// IndexError is reported when the instances of an indexed MatchGroup (see
+// MatchGroup.SetIndexed) do not form a valid list.
+type IndexError struct {
+	// Group is the name of the MatchGroup.
+	Group string
+
+	// Problem describes what is wrong with the list.
+	Problem IndexProblem
+
+	// Keys are the keys of the offending instance.  For IndexGap errors, this
+	// will be nil.
+	Keys []string
+
+	// Index is the index of the offending instance, or the first missing index
+	// for IndexGap errors.  For IndexInvalid errors, this will be -1.
+	Index int
+
+	// Last is the last missing index for IndexGap errors.
+	Last int
+
+	// Base is the first index of the list for IndexBelowBase errors.
+	Base int
+
+	// Other are the keys of the instance that was kept in place of the offending
+	// instance for IndexDuplicate errors.
+	Other []string
+}
+
+func (i *IndexError) Error() string {
+	switch i.Problem {
+	case IndexInvalid:
+		return fmt.Sprintf("match group %s (keys: %s): index is not a non-negative integer", i.Group, strings.Join(i.Keys, ","))
+	case IndexDuplicate:
+		return fmt.Sprintf("match group %s (keys: %s): index %d is already used by keys %s", i.Group, strings.Join(i.Keys, ","), i.Index, strings.Join(i.Other, ","))
+	case IndexGap:
+		if i.Index == i.Last {
+			return fmt.Sprintf("match group %s is missing index %d", i.Group, i.Index)
+		}
+		return fmt.Sprintf("match group %s is missing indices %d to %d", i.Group, i.Index, i.Last)
+	case IndexBelowBase:
+		return fmt.Sprintf("match group %s (keys: %s): index %d is below the first index %d", i.Group, strings.Join(i.Keys, ","), i.Index, i.Base)
+	default:
+		return fmt.Sprintf("match group %s has an invalid index", i.Group)
+	}
+}
+
+func (i *IndexError) Is(target error) bool {
+	return target == ErrIndex
+}
+
+func (i *IndexError) errorGroup() string   { return i.Group }
+func (i *IndexError) errorMatcher() string { return "" }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Orphan Error
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// OrphanError is reported when an instance of a child MatchGroup (see
+// MatchGroup.AddChild) does not belong to any instance of its parent.
+type OrphanError struct {
+	// Group is the name of the child MatchGroup.
+	Group string
+
+	// Parent is the name of the parent MatchGroup.
+	Parent string
+
+	// Keys are the keys of the child instance.
+	Keys []string
+}
+
+func (o *OrphanError) Error() string {
+	return fmt.Sprintf("match group %s (keys: %s) does not belong to any instance of parent group %s", o.Group, strings.Join(o.Keys, ","), o.Parent)
+}
+
+func (o *OrphanError) Is(target error) bool {
+	return target == ErrOrphan
+}
+
+func (o *OrphanError) errorGroup() string   { return o.Group }
+func (o *OrphanError) errorMatcher() string { return "" }
@@ -0,0 +1,66 @@
+package wenv_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestExclusiveMode(t *testing.T) {
+	Convey("exclusive mode", t, func() {
+		env := map[string]string{
+			"CACHE_MAIN_HOST":       "cache",
+			"CACHE_REDIS_MAIN_HOST": "redis",
+		}
+
+		newMatcher := func(redisPriority int) wenv.EnvironmentMatcher {
+			return wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("cache").
+					AddMatcher(wenv.NewWrappedMatcher("host", "CACHE_", "_HOST"), true),
+					false,
+				).
+				AddGroup(wenv.NewMatchGroup("redis").
+					SetPriority(redisPriority).
+					AddMatcher(wenv.NewWrappedMatcher("host", "CACHE_REDIS_", "_HOST"), true),
+					false,
+				)
+		}
+
+		Convey("when disabled", func() {
+			res := newMatcher(0).ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Claims(), ShouldBeNil)
+			So(res.Get("cache").Size(), ShouldEqual, 2)
+			So(res.Get("redis").Size(), ShouldEqual, 1)
+		})
+
+		Convey("in registration order", func() {
+			res := newMatcher(0).SetExclusive(true).ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("cache").Size(), ShouldEqual, 2)
+			So(res.Has("redis"), ShouldBeFalse)
+			So(res.Claims(), ShouldResemble, map[string]string{
+				"CACHE_MAIN_HOST":       "cache",
+				"CACHE_REDIS_MAIN_HOST": "cache",
+			})
+		})
+
+		Convey("in priority order", func() {
+			res := newMatcher(1).SetExclusive(true).ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("cache").Size(), ShouldEqual, 1)
+			So(res.Get("cache").Get(0).Get("host").Value(), ShouldEqual, "cache")
+			So(res.Get("redis").Size(), ShouldEqual, 1)
+			So(res.Get("redis").Get(0).Get("host").Value(), ShouldEqual, "redis")
+			So(res.Claims(), ShouldResemble, map[string]string{
+				"CACHE_MAIN_HOST":       "cache",
+				"CACHE_REDIS_MAIN_HOST": "redis",
+			})
+		})
+	})
+}
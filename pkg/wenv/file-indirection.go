@@ -0,0 +1,163 @@
+package wenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultFileSuffix is the variable name suffix used for file indirection when
+// FileOptions.Suffix is empty.
+const DefaultFileSuffix = "_FILE"
+
+// DefaultFileMaxSize is the maximum number of bytes read from a file by file
+// indirection when FileOptions.MaxSize is zero.
+const DefaultFileMaxSize = 1 << 20
+
+// FileTrim defines how values read by file indirection are trimmed.
+type FileTrim uint8
+
+const (
+	// TrimNone uses the contents of the file as-is.
+	TrimNone FileTrim = iota
+
+	// TrimNewline removes a single trailing "\n" or "\r\n" from the contents of
+	// the file.
+	TrimNewline
+
+	// TrimSpace removes all leading and trailing whitespace from the contents of
+	// the file.
+	TrimSpace
+)
+
+// FileOptions configures file indirection for a KeyMatcher.
+type FileOptions struct {
+	// Suffix is the suffix appended to a variable name to form the name of its
+	// file indirection variable.  Defaults to DefaultFileSuffix.
+	Suffix string
+
+	// Trim defines how the contents of the file are trimmed.  Defaults to
+	// TrimNone.
+	Trim FileTrim
+
+	// MaxSize is the maximum number of bytes that will be read from the file.
+	// Files larger than this are reported as errors.  Defaults to
+	// DefaultFileMaxSize; a negative value means there is no limit.
+	MaxSize int64
+}
+
+func (f FileOptions) suffix() string {
+	if f.Suffix == "" {
+		return DefaultFileSuffix
+	}
+	return f.Suffix
+}
+
+// WithFileIndirection wraps the given KeyMatcher so that its values may also be
+// provided through files, following the Docker secrets convention.
+//
+// When the wrapped KeyMatcher would match a variable name, a variable with the
+// same name followed by the configured suffix is treated as holding the path to
+// a file containing the value.  If both variables are present, the plain
+// variable takes precedence.  Values read from files are reported by
+// MatchResult.FilePath.
+//
+// If the file cannot be read, the MatchGroup reports a *FileError for the
+// variable, and the KeyMatcher is treated as not having matched.
+//
+// Example:
+//   // Matches both DB_FOO_PASSWORD=secret and
+//   // DB_FOO_PASSWORD_FILE=/run/secrets/foo
+//   matcher := WithFileIndirection(
+//     NewWrappedMatcher("password", "DB_", "_PASSWORD"),
+//     FileOptions{Trim: TrimNewline},
+//   )
+func WithFileIndirection(matcher KeyMatcher, options FileOptions) KeyMatcher {
+	if fm, ok := matcher.(*fileKeyMatcher); ok {
+		matcher = fm.KeyMatcher
+	}
+
+	return &fileKeyMatcher{matcher, options}
+}
+
+type fileKeyMatcher struct {
+	KeyMatcher
+	options FileOptions
+}
+
+func (f *fileKeyMatcher) KeyNames() []string {
+	if nkm, ok := f.KeyMatcher.(NamedKeyMatcher); ok {
+		return nkm.KeyNames()
+	}
+	return nil
+}
+
+func (f *fileKeyMatcher) Matches(key string) bool {
+	return f.indirect(key) || f.KeyMatcher.Matches(key)
+}
+
+func (f *fileKeyMatcher) Process(key string) []string {
+	if f.indirect(key) {
+		return f.KeyMatcher.Process(key[:len(key)-len(f.options.suffix())])
+	}
+	return f.KeyMatcher.Process(key)
+}
+
+func (f *fileKeyMatcher) Render(keys []string) (string, bool) {
+	if kr, ok := f.KeyMatcher.(KeyRenderer); ok {
+		return kr.Render(keys)
+	}
+	return "", false
+}
+
+// indirect tests whether the given key is a file indirection variable for the
+// wrapped KeyMatcher.
+func (f *fileKeyMatcher) indirect(key string) bool {
+	suffix := f.options.suffix()
+	return strings.HasSuffix(key, suffix) && f.KeyMatcher.Matches(key[:len(key)-len(suffix)])
+}
+
+// readFile reads the value from the file at the given path according to the
+// configured options.
+func (f *fileKeyMatcher) readFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("file path is empty")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	limit := f.options.MaxSize
+	if limit == 0 {
+		limit = DefaultFileMaxSize
+	}
+
+	var reader io.Reader = file
+	if limit > 0 {
+		reader = io.LimitReader(file, limit+1)
+	}
+
+	value, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	if limit > 0 && int64(len(value)) > limit {
+		return "", fmt.Errorf("file exceeds the maximum size of %d bytes", limit)
+	}
+
+	switch f.options.Trim {
+	case TrimNewline:
+		value = trimTrailingNewline(value)
+	case TrimSpace:
+		value = bytes.TrimSpace(value)
+	}
+
+	return string(value), nil
+}
@@ -0,0 +1,143 @@
+package wenv_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestFileIndirection(t *testing.T) {
+	Convey("file indirection", t, func() {
+		dir := t.TempDir()
+		secret := filepath.Join(dir, "secret")
+		big := filepath.Join(dir, "big")
+
+		So(os.WriteFile(secret, []byte("  hunter2\n"), 0600), ShouldBeNil)
+		So(os.WriteFile(big, []byte(strings.Repeat("x", 65)), 0600), ShouldBeNil)
+
+		Convey("on a single KeyMatcher", func() {
+			matcher := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewWrappedMatcher("user", "DB_", "_USER"), true).
+					AddMatcher(wenv.WithFileIndirection(wenv.NewWrappedMatcher("password", "DB_", "_PASSWORD"), wenv.FileOptions{Trim: wenv.TrimNewline}), true),
+					true,
+				)
+
+			res := matcher.ParseEnv(map[string]string{
+				"DB_FOO_USER":          "foo",
+				"DB_FOO_PASSWORD_FILE": secret,
+				"DB_BAR_USER":          "bar",
+				"DB_BAR_PASSWORD":      "direct",
+				"DB_BAR_PASSWORD_FILE": secret,
+				"DB_BAZ_USER_FILE":     secret,
+			})
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("db").Size(), ShouldEqual, 2)
+
+			for i := 0; i < res.Get("db").Size(); i++ {
+				db := res.Get("db").Get(i)
+
+				switch db.FirstKey() {
+				case "FOO":
+					So(db.Value("password"), ShouldEqual, "  hunter2")
+					So(db.Get("password").Raw(), ShouldEqual, "DB_FOO_PASSWORD_FILE")
+					So(db.Get("password").FilePath(), ShouldEqual, secret)
+					So(db.Get("user").FilePath(), ShouldEqual, "")
+				case "BAR":
+					So(db.Value("password"), ShouldEqual, "direct")
+					So(db.Get("password").FilePath(), ShouldEqual, "")
+				default:
+					So(db.FirstKey(), ShouldBeIn, "FOO", "BAR")
+				}
+			}
+		})
+
+		Convey("on a whole MatchGroup", func() {
+			matcher := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewWrappedMatcher("user", "DB_", "_USER"), true).
+					AddMatcher(wenv.NewWrappedMatcher("password", "DB_", "_PASSWORD"), true).
+					SetFileIndirection(wenv.FileOptions{Suffix: "_PATH", Trim: wenv.TrimSpace, MaxSize: 64}),
+					true,
+				)
+
+			res := matcher.ParseEnv(map[string]string{
+				"DB_FOO_USER":          "foo",
+				"DB_FOO_PASSWORD_PATH": secret,
+				"DB_BAR_USER_PATH":     secret,
+				"DB_BAR_PASSWORD_PATH": big,
+				"DB_BAZ_USER":          "baz",
+				"DB_BAZ_PASSWORD_PATH": filepath.Join(dir, "missing"),
+			})
+
+			So(res.Errors().Size(), ShouldEqual, 2)
+
+			var fileErr *wenv.FileError
+			for _, err := range res.Errors() {
+				So(errors.As(err, &fileErr), ShouldBeTrue)
+				So(fileErr.Matcher, ShouldEqual, "password")
+
+				switch fileErr.Keys[0] {
+				case "BAR":
+					So(err.Error(), ShouldEqual, "match group db (keys: BAR): cannot read file "+strconv.Quote(big)+" given by DB_BAR_PASSWORD_PATH for key password: file exceeds the maximum size of 64 bytes")
+				case "BAZ":
+					So(errors.Is(err, fs.ErrNotExist), ShouldBeTrue)
+				}
+			}
+
+			for i := 0; i < res.Get("db").Size(); i++ {
+				db := res.Get("db").Get(i)
+
+				switch db.FirstKey() {
+				case "FOO":
+					So(db.Value("password"), ShouldEqual, "hunter2")
+				case "BAR":
+					So(db.Value("user"), ShouldEqual, "hunter2")
+					So(db.Has("password"), ShouldBeFalse)
+				case "BAZ":
+					So(db.Has("password"), ShouldBeFalse)
+				}
+			}
+		})
+
+		Convey("with an empty file path", func() {
+			matcher := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.WithFileIndirection(wenv.NewWrappedMatcher("password", "DB_", "_PASSWORD"), wenv.FileOptions{}), true),
+					false,
+				)
+
+			Convey("next to a direct value", func() {
+				res := matcher.ParseEnv(map[string]string{
+					"DB_FOO_PASSWORD":      "secret",
+					"DB_FOO_PASSWORD_FILE": "",
+				})
+
+				So(res.Errors(), ShouldBeNil)
+				So(res.Get("db").Get(0).Value("password"), ShouldEqual, "secret")
+				So(res.Get("db").Get(0).Get("password").Raw(), ShouldEqual, "DB_FOO_PASSWORD")
+			})
+
+			Convey("on its own", func() {
+				res := matcher.ParseEnv(map[string]string{"DB_FOO_PASSWORD_FILE": ""})
+
+				So(res.Errors().Size(), ShouldEqual, 1)
+
+				var fileErr *wenv.FileError
+				So(errors.As(res.Errors().Get(0), &fileErr), ShouldBeTrue)
+				So(fileErr.Variable, ShouldEqual, "DB_FOO_PASSWORD_FILE")
+				So(fileErr.Error(), ShouldEqual, "match group db (keys: FOO): cannot read file \"\" given by DB_FOO_PASSWORD_FILE for key password: file path is empty")
+				So(res.Has("db"), ShouldBeFalse)
+			})
+		})
+	})
+}
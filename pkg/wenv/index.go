@@ -0,0 +1,174 @@
+package wenv
+
+import (
+	"cmp"
+	"slices"
+)
+
+// matcherIndex indexes the KeyMatchers of a set of compiled MatchGroups by the
+// literal prefixes and suffixes of the variable names they match.
+//
+// The index allows each environment variable to be checked only against the
+// KeyMatchers that could possibly match it, rather than against every
+// KeyMatcher of every MatchGroup.  KeyMatchers whose names have no literal
+// prefix or suffix, such as those created by NewRegexMatcher or implemented
+// outside of this package, are checked against every variable.
+type matcherIndex struct {
+	prefixes trieNode
+
+	// suffixes is keyed by the reversed suffixes of the indexed KeyMatchers.
+	suffixes trieNode
+	fallback []indexEntry
+
+	// rank holds the position of each group in the order the groups are given
+	// the environment variables.
+	rank []int
+}
+
+// indexEntry identifies a single KeyMatcher by the index of its group and its
+// index within that group.  An entry whose matcher is childMarker stands for
+// the KeyMatchers of the group's descendants.
+type indexEntry struct {
+	group   int
+	matcher int
+}
+
+type trieNode struct {
+	children map[byte]*trieNode
+	entries  []indexEntry
+}
+
+// newMatcherIndex builds an index over the KeyMatchers of the given groups,
+// which are given the environment variables in the given order.
+func newMatcherIndex(groups []*compiledMatchGroup, order []int) *matcherIndex {
+	out := &matcherIndex{rank: make([]int, len(groups))}
+
+	for rank, gi := range order {
+		out.rank[gi] = rank
+	}
+
+	for gi, group := range groups {
+		for mi, km := range group.matchers {
+			out.insert(km, indexEntry{gi, mi})
+		}
+
+		// The KeyMatchers of the group's descendants are all indexed under the
+		// marker for the group's children.
+		var descend func(*compiledMatchGroup)
+		descend = func(g *compiledMatchGroup) {
+			for _, child := range g.children {
+				for _, km := range child.matchers {
+					out.insert(km, indexEntry{gi, childMarker})
+				}
+				descend(child)
+			}
+		}
+		descend(group)
+	}
+
+	return out
+}
+
+// insert adds the given entry to the index for the given KeyMatcher.
+func (m *matcherIndex) insert(km KeyMatcher, entry indexEntry) {
+	prefix, suffixes, ok := indexAffixes(km)
+
+	switch {
+	case ok && prefix != "":
+		m.prefixes.insert(prefix, false, entry)
+	case ok && !slices.Contains(suffixes, ""):
+		for _, suffix := range suffixes {
+			m.suffixes.insert(suffix, true, entry)
+		}
+	default:
+		m.fallback = append(m.fallback, entry)
+	}
+}
+
+// lookup appends the KeyMatchers that may match the given variable name to the
+// given buffer, ordered by group rank and then by their position within their
+// group.
+func (m *matcherIndex) lookup(name string, buf []indexEntry) []indexEntry {
+	buf = m.prefixes.collect(name, false, buf)
+	buf = m.suffixes.collect(name, true, buf)
+	buf = append(buf, m.fallback...)
+
+	slices.SortFunc(buf, func(a, b indexEntry) int {
+		if c := cmp.Compare(m.rank[a.group], m.rank[b.group]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.matcher, b.matcher)
+	})
+
+	// A KeyMatcher with several suffixes, or the descendants of a group, may be
+	// found more than once.
+	return slices.Compact(buf)
+}
+
+// insert adds the given entry under the given key, reading the key backwards if
+// reverse is true.
+func (t *trieNode) insert(key string, reverse bool, entry indexEntry) {
+	node := t
+
+	for i := range key {
+		c := key[i]
+		if reverse {
+			c = key[len(key)-1-i]
+		}
+
+		next, ok := node.children[c]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[byte]*trieNode, 4)
+			}
+			next = new(trieNode)
+			node.children[c] = next
+		}
+
+		node = next
+	}
+
+	node.entries = append(node.entries, entry)
+}
+
+// collect appends the entries of every key that is a prefix of the given name
+// (or a suffix, if reverse is true) to the given buffer.
+func (t *trieNode) collect(name string, reverse bool, buf []indexEntry) []indexEntry {
+	node := t
+
+	for i := 0; i < len(name) && node.children != nil; i++ {
+		c := name[i]
+		if reverse {
+			c = name[len(name)-1-i]
+		}
+
+		if node = node.children[c]; node == nil {
+			break
+		}
+
+		buf = append(buf, node.entries...)
+	}
+
+	return buf
+}
+
+// indexAffixes returns the literal prefix and the possible literal suffixes of
+// the variable names matched by the given KeyMatcher.
+//
+// If the affixes of the KeyMatcher are not known, ok will be false.
+func indexAffixes(km KeyMatcher) (prefix string, suffixes []string, ok bool) {
+	if fm, isFile := km.(*fileKeyMatcher); isFile {
+		if prefix, suffixes, ok = indexAffixes(fm.KeyMatcher); ok {
+			suffixes = append(suffixes, suffixes[0]+fm.options.suffix())
+		}
+		return
+	}
+
+	if a, isAffixer := km.(affixer); isAffixer {
+		var suffix string
+		prefix, suffix = a.affixes()
+		return prefix, []string{suffix}, true
+	}
+
+	return "", nil, false
+}
@@ -0,0 +1,118 @@
+package wenv
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// indexTestMatcher returns a matcher with the given number of groups, each
+// using a mix of the KeyMatcher kinds, along with an environment containing the
+// given number of variables for each group plus the given number of unrelated
+// variables.
+func indexTestMatcher(groups, perGroup, noise int) (*compiledEnvironmentMatcher, map[string]string) {
+	matcher := NewEnvironmentMatcher()
+	env := make(map[string]string, groups*perGroup*4+noise)
+
+	for g := 0; g < groups; g++ {
+		prefix := fmt.Sprintf("SVC%d_", g)
+
+		matcher.AddGroup(NewMatchGroup(prefix).
+			AddMatcher(NewWrappedMatcher("host", prefix, "_HOST"), true).
+			AddMatcher(NewWrappedMatcher("port", prefix, "_PORT"), false).
+			AddMatcher(NewPatternMatcher("user", prefix+"{id}_USER"), false).
+			AddMatcher(WithFileIndirection(NewSuffixMatcher("pass", fmt.Sprintf("_SVC%d_PASSWORD", g)), FileOptions{}), false),
+			false,
+		)
+
+		for i := 0; i < perGroup; i++ {
+			env[fmt.Sprintf("%sI%d_HOST", prefix, i)] = "localhost"
+			env[fmt.Sprintf("%sI%d_PORT", prefix, i)] = "8080"
+			env[fmt.Sprintf("%sI%d_USER", prefix, i)] = "user"
+			env[fmt.Sprintf("I%d_SVC%d_PASSWORD", i, g)] = "pass"
+		}
+	}
+
+	matcher.AddGroup(NewMatchGroup("regex").
+		AddMatcher(NewRegexMatcher("value", regexp.MustCompile(`^RX_(\w+)_VALUE$`)), true),
+		false,
+	)
+	env["RX_FOO_VALUE"] = "foo"
+
+	for i := 0; i < noise; i++ {
+		env[fmt.Sprintf("UNRELATED_VARIABLE_%d", i)] = "noise"
+	}
+
+	compiled, err := matcher.(*environmentMatcher).compile()
+	if err != nil {
+		panic(err)
+	}
+
+	return compiled, env
+}
+
+func TestMatcherIndex(t *testing.T) {
+	Convey("matcher index", t, func() {
+		indexed, env := indexTestMatcher(8, 4, 64)
+		linear := *indexed
+		linear.index = nil
+
+		Convey("finds the same matches as a linear scan", func() {
+			a := indexed.ParseEnv(env)
+			b := linear.ParseEnv(env)
+
+			So(a.Errors(), ShouldBeNil)
+			So(b.Errors(), ShouldBeNil)
+			So(a.Size(), ShouldEqual, b.Size())
+			So(a.Get("regex").Size(), ShouldEqual, 1)
+
+			for g := 0; g < 8; g++ {
+				name := fmt.Sprintf("SVC%d_", g)
+				So(a.Get(name).Size(), ShouldEqual, 4)
+				So(b.Get(name).Size(), ShouldEqual, 4)
+
+				for i := 0; i < 4; i++ {
+					So(a.Get(name).Get(i).Size(), ShouldEqual, 4)
+				}
+			}
+		})
+
+		Convey("only returns candidate matchers", func() {
+			So(indexed.index.lookup("SVC3_I0_HOST", nil), ShouldResemble, []indexEntry{{3, 0}, {3, 1}, {3, 2}, {8, 0}})
+			So(indexed.index.lookup("I0_SVC3_PASSWORD_FILE", nil), ShouldResemble, []indexEntry{{3, 3}, {8, 0}})
+			So(indexed.index.lookup("UNRELATED_VARIABLE_1", nil), ShouldResemble, []indexEntry{{8, 0}})
+		})
+	})
+}
+
+func BenchmarkParseEnv(b *testing.B) {
+	sizes := []struct{ groups, perGroup, noise int }{
+		{4, 4, 100},
+		{24, 8, 2000},
+		{48, 16, 5000},
+	}
+
+	for _, size := range sizes {
+		indexed, env := indexTestMatcher(size.groups, size.perGroup, size.noise)
+		linear := *indexed
+		linear.index = nil
+
+		name := fmt.Sprintf("groups=%d/vars=%d", size.groups, len(env))
+
+		b.Run(name+"/indexed", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				indexed.ParseEnv(env)
+			}
+		})
+
+		b.Run(name+"/linear", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				linear.ParseEnv(env)
+			}
+		})
+	}
+}
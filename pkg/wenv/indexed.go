@@ -0,0 +1,144 @@
+package wenv
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+)
+
+// IndexOptions configures a MatchGroup whose instances form a list, with the
+// first key of each instance being its integer index.
+//
+// For example, the following variables would be parsed into a list of two
+// instances with the indices 0 and 1:
+//   SERVER_0_HOST=a.example.com
+//   SERVER_1_HOST=b.example.com
+type IndexOptions struct {
+	// Base is the index of the first element of the list.  Only used when
+	// Contiguous is true.  Typically 0 or 1.
+	Base int
+
+	// Contiguous requires the indices to start at Base and have no gaps.
+	Contiguous bool
+}
+
+// IndexProblem describes why an *IndexError was reported.
+type IndexProblem uint8
+
+const (
+	// IndexInvalid is reported for an instance whose index is not a
+	// non-negative integer.
+	IndexInvalid IndexProblem = iota + 1
+
+	// IndexDuplicate is reported for an instance whose index has already been
+	// used by another instance, such as "01" and "1".
+	IndexDuplicate
+
+	// IndexGap is reported for a range of indices that have no instance in a
+	// contiguous list.
+	IndexGap
+
+	// IndexBelowBase is reported for an instance whose index is less than the
+	// Base of a contiguous list.
+	IndexBelowBase
+)
+
+// parseIndex parses the given key as a list index.  Only plain, unsigned
+// decimal numbers are accepted.
+func parseIndex(key string) (int, bool) {
+	if key == "" {
+		return 0, false
+	}
+
+	for i := range key {
+		if !isDigit(key[i]) {
+			return 0, false
+		}
+	}
+
+	out, err := strconv.Atoi(key)
+	return out, err == nil
+}
+
+// indexInstances assigns each of the given instances its index, returning the
+// instances ordered by index along with the errors found in the list.
+//
+// The index of each instance is its key at the given offset.  Instances whose
+// indices are invalid or duplicated are left out, along with their errors.
+// Instances without a result are kept after the others.
+func (m *compiledMatchGroup) indexInstances(instances []groupInstance, offset int) ([]groupInstance, []error) {
+	var errors []error
+
+	valid := make([]groupInstance, 0, len(instances))
+	var rest []groupInstance
+
+	for _, inst := range instances {
+		if inst.result == nil {
+			rest = append(rest, inst)
+		} else if idx, ok := parseIndex(indexKey(inst.result.keys, offset)); ok {
+			inst.result.index, inst.result.indexed = idx, true
+			valid = append(valid, inst)
+		} else {
+			errors = append(errors, &IndexError{Group: m.name, Problem: IndexInvalid, Keys: inst.result.keys, Index: -1})
+		}
+	}
+
+	// Order by index, preferring the canonical form of each index so that "1"
+	// is kept over "01".
+	slices.SortStableFunc(valid, func(a, b groupInstance) int {
+		if a.result.index != b.result.index {
+			return cmp.Compare(a.result.index, b.result.index)
+		}
+		if ak, bk := indexKey(a.result.keys, offset), indexKey(b.result.keys, offset); len(ak) != len(bk) {
+			return cmp.Compare(len(ak), len(bk))
+		}
+		return slices.Compare(a.result.keys, b.result.keys)
+	})
+
+	out := valid[:0]
+	next := m.indexed.Base
+
+	for _, inst := range valid {
+		res := inst.result
+
+		if len(out) > 0 && out[len(out)-1].result.index == res.index {
+			errors = append(errors, &IndexError{
+				Group:   m.name,
+				Problem: IndexDuplicate,
+				Keys:    res.keys,
+				Index:   res.index,
+				Other:   out[len(out)-1].result.keys,
+			})
+			continue
+		}
+
+		if m.indexed.Contiguous && res.index < m.indexed.Base {
+			errors = append(errors, &IndexError{
+				Group:   m.name,
+				Problem: IndexBelowBase,
+				Keys:    res.keys,
+				Index:   res.index,
+				Base:    m.indexed.Base,
+			})
+			continue
+		}
+
+		if m.indexed.Contiguous && res.index > next {
+			errors = append(errors, &IndexError{Group: m.name, Problem: IndexGap, Index: next, Last: res.index - 1})
+		}
+
+		next = res.index + 1
+		out = append(out, inst)
+	}
+
+	return append(out, rest...), errors
+}
+
+// indexKey returns the key at the given offset, or an empty string if there is
+// no such key.
+func indexKey(keys []string, offset int) string {
+	if offset < len(keys) {
+		return keys[offset]
+	}
+	return ""
+}
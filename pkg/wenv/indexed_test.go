@@ -0,0 +1,155 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestIndexedGroups(t *testing.T) {
+	Convey("indexed match groups", t, func() {
+		parse := func(options wenv.IndexOptions, env map[string]string) wenv.EnvMatchResult {
+			return wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("servers").
+					AddMatcher(wenv.NewWrappedMatcher("host", "SERVER_", "_HOST"), true).
+					SetIndexed(options),
+					true,
+				).
+				ParseEnv(env)
+		}
+
+		Convey("are ordered by index", func() {
+			res := parse(wenv.IndexOptions{Contiguous: true}, map[string]string{
+				"SERVER_10_HOST": "k",
+				"SERVER_2_HOST":  "c",
+				"SERVER_0_HOST":  "a",
+				"SERVER_1_HOST":  "b",
+				"SERVER_3_HOST":  "d",
+				"SERVER_4_HOST":  "e",
+				"SERVER_5_HOST":  "f",
+				"SERVER_6_HOST":  "g",
+				"SERVER_7_HOST":  "h",
+				"SERVER_8_HOST":  "i",
+				"SERVER_9_HOST":  "j",
+			})
+
+			So(res.Errors(), ShouldBeNil)
+
+			servers := res.Get("servers")
+			So(servers.Size(), ShouldEqual, 11)
+			for i := 0; i < servers.Size(); i++ {
+				idx, ok := servers.Get(i).Index()
+				So(ok, ShouldBeTrue)
+				So(idx, ShouldEqual, i)
+				So(servers.Get(i).Get("host").Value(), ShouldEqual, string(rune('a'+i)))
+			}
+		})
+
+		Convey("report invalid indices", func() {
+			res := parse(wenv.IndexOptions{}, map[string]string{
+				"SERVER_0_HOST":   "a",
+				"SERVER_ONE_HOST": "b",
+				"SERVER_-2_HOST":  "c",
+			})
+
+			So(res.Get("servers").Size(), ShouldEqual, 1)
+			So(res.Errors(), ShouldHaveLength, 2)
+			So(errors.Is(res.Errors(), wenv.ErrIndex), ShouldBeTrue)
+			So(res.Errors()[0].Error(), ShouldEqual, "match group servers (keys: -2): index is not a non-negative integer")
+			So(res.Errors()[1].(*wenv.IndexError).Problem, ShouldEqual, wenv.IndexInvalid)
+		})
+
+		Convey("report instances without keys", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("servers").
+					AddMatcher(keylessMatcher{"host", "SERVER_HOST"}, true).
+					SetIndexed(wenv.IndexOptions{}),
+					false,
+				).
+				ParseEnv(map[string]string{"SERVER_HOST": "a"})
+
+			So(res.Errors(), ShouldHaveLength, 1)
+			So(res.Errors()[0].(*wenv.IndexError).Problem, ShouldEqual, wenv.IndexInvalid)
+			So(res.Errors()[0].Error(), ShouldEqual, "match group servers (keys: ): index is not a non-negative integer")
+		})
+
+		Convey("report duplicate indices", func() {
+			res := parse(wenv.IndexOptions{}, map[string]string{
+				"SERVER_01_HOST": "zero-one",
+				"SERVER_1_HOST":  "one",
+			})
+
+			So(res.Get("servers").Size(), ShouldEqual, 1)
+			So(res.Get("servers").Get(0).Get("host").Value(), ShouldEqual, "one")
+			So(res.Errors(), ShouldHaveLength, 1)
+
+			ie := res.Errors()[0].(*wenv.IndexError)
+			So(ie.Problem, ShouldEqual, wenv.IndexDuplicate)
+			So(ie.Keys, ShouldResemble, []string{"01"})
+			So(ie.Other, ShouldResemble, []string{"1"})
+			So(ie.Error(), ShouldEqual, "match group servers (keys: 01): index 1 is already used by keys 1")
+		})
+
+		Convey("report gaps when contiguous", func() {
+			env := map[string]string{
+				"SERVER_1_HOST": "a",
+				"SERVER_2_HOST": "b",
+				"SERVER_5_HOST": "c",
+			}
+
+			res := parse(wenv.IndexOptions{Base: 1, Contiguous: true}, env)
+			So(res.Get("servers").Size(), ShouldEqual, 3)
+			So(res.Errors(), ShouldHaveLength, 1)
+			So(res.Errors()[0].Error(), ShouldEqual, "match group servers is missing indices 3 to 4")
+
+			res = parse(wenv.IndexOptions{Contiguous: true}, env)
+			So(res.Errors(), ShouldHaveLength, 2)
+			So(res.Errors()[0].Error(), ShouldEqual, "match group servers is missing index 0")
+
+			report := res.Errors().Report()
+			So(report[0].Kind, ShouldEqual, wenv.ErrorKindIndex)
+
+			res = parse(wenv.IndexOptions{}, env)
+			So(res.Errors(), ShouldBeNil)
+		})
+
+		Convey("report indices below the base when contiguous", func() {
+			env := map[string]string{
+				"SERVER_0_HOST": "a",
+				"SERVER_1_HOST": "b",
+				"SERVER_2_HOST": "c",
+			}
+
+			res := parse(wenv.IndexOptions{Base: 1, Contiguous: true}, env)
+			So(res.Get("servers").Size(), ShouldEqual, 2)
+			So(res.Get("servers").Get(0).Get("host").Value(), ShouldEqual, "b")
+			So(res.Errors(), ShouldHaveLength, 1)
+
+			ie := res.Errors()[0].(*wenv.IndexError)
+			So(ie.Problem, ShouldEqual, wenv.IndexBelowBase)
+			So(ie.Keys, ShouldResemble, []string{"0"})
+			So(ie.Base, ShouldEqual, 1)
+			So(ie.Last, ShouldEqual, 0)
+			So(ie.Error(), ShouldEqual, "match group servers (keys: 0): index 0 is below the first index 1")
+
+			res = parse(wenv.IndexOptions{Base: 1}, env)
+			So(res.Get("servers").Size(), ShouldEqual, 3)
+			So(res.Errors(), ShouldBeNil)
+		})
+
+		Convey("are not indexed by default", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("servers").
+					AddMatcher(wenv.NewWrappedMatcher("host", "SERVER_", "_HOST"), true),
+					true,
+				).
+				ParseEnv(map[string]string{"SERVER_0_HOST": "a"})
+
+			_, ok := res.Get("servers").Get(0).Index()
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package wenv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// InstanceID identifies a single instance of a MatchGroup by the keys that
+// were extracted from its environment variable names.
+//
+// InstanceIDs are comparable and may be used as map keys.  Two InstanceIDs are
+// equal if and only if they were built from the same keys in the same order;
+// unlike joining the keys with a separator, keys that contain the separator do
+// not collide:
+//   NewInstanceID("A,B", "C") == NewInstanceID("A", "B,C") // false
+//
+// The zero InstanceID is the ID of an instance with no keys.
+type InstanceID struct {
+	// encoded holds each key prefixed with its length.
+	encoded string
+}
+
+// NewInstanceID returns the InstanceID for the given keys.
+//
+// Example:
+//   result.Get("db").Get(0).ID() == NewInstanceID("FOO")
+func NewInstanceID(keys ...string) InstanceID {
+	sb := new(strings.Builder)
+
+	for _, key := range keys {
+		sb.WriteString(strconv.Itoa(len(key)))
+		sb.WriteByte(':')
+		sb.WriteString(key)
+	}
+
+	return InstanceID{sb.String()}
+}
+
+// Keys returns the keys the InstanceID was built from.
+func (i InstanceID) Keys() []string {
+	var out []string
+
+	for rest := i.encoded; rest != ""; {
+		sep := strings.IndexByte(rest, ':')
+		n, _ := strconv.Atoi(rest[:sep])
+		out = append(out, rest[sep+1:sep+1+n])
+		rest = rest[sep+1+n:]
+	}
+
+	return out
+}
+
+// String returns the keys of the InstanceID in a form suitable for display,
+// such as ["A,B" "C"].
+func (i InstanceID) String() string {
+	keys := i.Keys()
+	quoted := make([]string, len(keys))
+
+	for j, key := range keys {
+		quoted[j] = strconv.Quote(key)
+	}
+
+	return "[" + strings.Join(quoted, " ") + "]"
+}
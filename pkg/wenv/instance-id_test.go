@@ -0,0 +1,75 @@
+package wenv_test
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+// keylessMatcher matches a single variable name without extracting any keys.
+type keylessMatcher struct{ name, variable string }
+
+func (k keylessMatcher) Name() string                { return k.name }
+func (k keylessMatcher) Matches(key string) bool     { return key == k.variable }
+func (k keylessMatcher) Process(key string) []string { return nil }
+
+func TestInstanceID(t *testing.T) {
+	Convey("InstanceID", t, func() {
+		Convey("does not collide for keys containing separators", func() {
+			So(wenv.NewInstanceID("A,B", "C"), ShouldNotEqual, wenv.NewInstanceID("A", "B,C"))
+			So(wenv.NewInstanceID("A,B", "C"), ShouldEqual, wenv.NewInstanceID("A,B", "C"))
+			So(wenv.NewInstanceID(), ShouldEqual, wenv.InstanceID{})
+			So(wenv.NewInstanceID(""), ShouldNotEqual, wenv.InstanceID{})
+		})
+
+		Convey("round trips its keys", func() {
+			id := wenv.NewInstanceID("A,B", "", "1:2")
+			So(id.Keys(), ShouldResemble, []string{"A,B", "", "1:2"})
+			So(id.String(), ShouldEqual, `["A,B" "" "1:2"]`)
+			So(wenv.InstanceID{}.Keys(), ShouldBeNil)
+		})
+
+		Convey("identifies match group results", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("pairs").
+					AddMatcher(wenv.NewRegexMatcher("value", regexp.MustCompile(`^PAIR_([^|]+)\|([^|]+)$`)), true),
+					true,
+				).
+				ParseEnv(map[string]string{
+					"PAIR_A,B|C": "first",
+					"PAIR_A|B,C": "second",
+				})
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("pairs").Size(), ShouldEqual, 2)
+
+			ids := make(map[wenv.InstanceID]string, 2)
+			for i := 0; i < res.Get("pairs").Size(); i++ {
+				r := res.Get("pairs").Get(i)
+				So(r.ID(), ShouldEqual, wenv.NewInstanceID(r.Keys()...))
+				ids[r.ID()] = r.Get("value").Value()
+			}
+
+			So(ids[wenv.NewInstanceID("A,B", "C")], ShouldEqual, "first")
+			So(ids[wenv.NewInstanceID("A", "B,C")], ShouldEqual, "second")
+		})
+
+		Convey("supports matchers that extract no keys", func() {
+			matcher := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("single").
+					AddMatcher(keylessMatcher{"value", "SINGLE_VALUE"}, true),
+					true,
+				)
+
+			for i := 0; i < 2; i++ {
+				res := matcher.ParseEnv(map[string]string{"SINGLE_VALUE": "foo"})
+				So(res.Errors(), ShouldBeNil)
+				So(res.Get("single").Get(0).ID(), ShouldEqual, wenv.InstanceID{})
+				So(res.Get("single").Get(0).Get("value").Value(), ShouldEqual, "foo")
+			}
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestIterators(t *testing.T) {
+	Convey("iterators", t, func() {
+		res := wenv.NewEnvironmentMatcher().
+			AddGroup(wenv.NewMatchGroup("plugins").
+				AddMatcher(wenv.NewPrefixMatcher("name", "PLUGIN_NAME_"), true).
+				AddMatcher(wenv.NewPrefixMatcher("path", "PLUGIN_PATH_"), true),
+				false,
+			).
+			AddGroup(wenv.NewMatchGroup("cache"), true).
+			AddGroup(wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), true).
+				AddMatcher(wenv.NewWrappedMatcher("host", "DB_", "_HOST"), true),
+				true,
+			).
+			ParseEnv(map[string]string{
+				"PLUGIN_NAME_ORANGE": "Orange",
+				"PLUGIN_PATH_ORANGE": "/opt/orange",
+				"PLUGIN_NAME_PURPLE": "Purple",
+				"DB_FOO_HOST":        "localhost",
+				"DB_FOO_PORT":        "5432",
+			})
+
+		Convey("over groups", func() {
+			So(res.Names(), ShouldResemble, []string{"plugins", "db"})
+
+			var names []string
+			for name, results := range res.Groups() {
+				So(results, ShouldEqual, res.Get(name))
+				names = append(names, name)
+			}
+			So(names, ShouldResemble, res.Names())
+
+			for range res.Groups() {
+				break
+			}
+		})
+
+		Convey("over group results", func() {
+			var keys []string
+			for i, instance := range res.Get("plugins").All() {
+				So(instance, ShouldEqual, res.Get("plugins").Get(i))
+				keys = append(keys, instance.FirstKey())
+			}
+			So(keys, ShouldResemble, []string{"ORANGE", "PURPLE"})
+		})
+
+		Convey("over matches", func() {
+			instance := res.Get("db").Get(0)
+			So(instance.Names(), ShouldResemble, []string{"port", "host"})
+
+			values := make(map[string]string)
+			for name, match := range instance.Matches() {
+				values[name] = match.Raw()
+			}
+			So(values, ShouldResemble, map[string]string{"port": "DB_FOO_PORT", "host": "DB_FOO_HOST"})
+
+			So(res.Get("plugins").Get(1).Names(), ShouldResemble, []string{"name"})
+		})
+
+		Convey("over errors", func() {
+			So(res.Errors(), ShouldHaveLength, 2)
+
+			var errs []error
+			for i, err := range res.Errors().All() {
+				So(err, ShouldEqual, res.Errors().Get(i))
+				errs = append(errs, err)
+			}
+			So(errs, ShouldHaveLength, 2)
+			So(errors.Is(errs[0], wenv.ErrMissingKey), ShouldBeTrue)
+			So(errors.Is(errs[1], wenv.ErrMissingGroup), ShouldBeTrue)
+		})
+	})
+}
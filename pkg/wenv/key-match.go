@@ -3,6 +3,7 @@ package wenv
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -23,6 +24,23 @@ type KeyMatcher interface {
 	Process(key string) []string
 }
 
+// KeyRenderer is a KeyMatcher that is able to render the environment variable
+// name it expects for a given set of keys.
+//
+// Rendered names are used to tell users exactly which variable to set when a
+// required key is missing.
+type KeyRenderer interface {
+	KeyMatcher
+
+	// Render returns the environment variable name this KeyMatcher would match
+	// for the given keys, or false if no such name can be built from the keys.
+	//
+	// Example:
+	//   NewWrappedMatcher("port", "DB_", "_PORT").(KeyRenderer).Render([]string{"FOO"})
+	//   // "DB_FOO_PORT", true
+	Render(keys []string) (string, bool)
+}
+
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 //
 //    Prefix Key Matcher
@@ -65,6 +83,13 @@ func (p *prefixKeyMatcher) Process(key string) []string {
 	return []string{key[len(p.prefix):]}
 }
 
+func (p *prefixKeyMatcher) Render(keys []string) (string, bool) {
+	if len(keys) != 1 {
+		return "", false
+	}
+	return p.prefix + keys[0], true
+}
+
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 //
 //    Suffix Key Matcher
@@ -107,6 +132,13 @@ func (s *suffixKeyMatcher) Process(key string) []string {
 	return []string{key[:len(key)-len(s.suffix)]}
 }
 
+func (s *suffixKeyMatcher) Render(keys []string) (string, bool) {
+	if len(keys) != 1 {
+		return "", false
+	}
+	return keys[0] + s.suffix, true
+}
+
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 //
 //    Wrapped Key Matcher
@@ -150,6 +182,13 @@ func (w *wrappedKeyMatcher) Process(key string) []string {
 	return []string{key[len(w.prefix) : len(key)-len(w.suffix)]}
 }
 
+func (w *wrappedKeyMatcher) Render(keys []string) (string, bool) {
+	if len(keys) != 1 {
+		return "", false
+	}
+	return w.prefix + keys[0] + w.suffix, true
+}
+
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 //
 //    Regex Key Matcher
@@ -162,9 +201,14 @@ func (w *wrappedKeyMatcher) Process(key string) []string {
 // The given regex must contain at least one matching group, otherwise this key
 // matcher will error when used.
 //
+// If the given regex contains named capture groups, the names of those groups
+// will be used as the key names for the matcher (see NamedKeyMatcher).  The
+// capture groups must then all be named, otherwise this function panics.
+//
 // Examples:
 //   matcher := NewRegexMatcher(regexp.MustCompile(`^PLUGIN_(\w+)_NAME$`))
 //   matcher := NewRegexMatcher(regexp.MustCompile(`^PLUGIN_(\w+)_(\w+)_NAME`))
+//   matcher := NewRegexMatcher("url", regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_URL$`))
 //
 // This type of matcher is useful if the environment variable matching is
 // complex or multiple wildcard keys need to be parsed from the environment
@@ -176,18 +220,36 @@ func (w *wrappedKeyMatcher) Process(key string) []string {
 // In this example, the regex used to match and parse the above environment
 // variables would be `^FRUIT_PAIR_(\w+)_(\w+)$`.
 func NewRegexMatcher(name string, regex *regexp.Regexp) KeyMatcher {
-	return &regexKeyMatcher{name, regex}
+	out := &regexKeyMatcher{name: name, regex: regex}
+
+	// Only use the capture group names if at least one of the groups is named.
+	// The slice returned by SubexpNames must not be modified, so it is copied.
+	names := regex.SubexpNames()[1:]
+	if slices.ContainsFunc(names, func(sub string) bool { return sub != "" }) {
+		if slices.Contains(names, "") {
+			panic(fmt.Errorf("invalid regex %q for key matcher %s: capture groups must be either all named or all unnamed", regex, name))
+		}
+
+		out.names = slices.Clone(names)
+	}
+
+	return out
 }
 
 type regexKeyMatcher struct {
 	name  string
 	regex *regexp.Regexp
+	names []string
 }
 
 func (r *regexKeyMatcher) Name() string {
 	return r.name
 }
 
+func (r *regexKeyMatcher) KeyNames() []string {
+	return slices.Clone(r.names)
+}
+
 func (r *regexKeyMatcher) Matches(key string) bool {
 	return r.regex.MatchString(key)
 }
@@ -205,3 +267,279 @@ func (r *regexKeyMatcher) Process(key string) []string {
 
 	return matches[1:]
 }
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Pattern Key Matcher
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// NamedKeyMatcher is a KeyMatcher that is able to name the individual keys it
+// extracts from environment variable names.
+//
+// When a MatchGroup contains a NamedKeyMatcher, the keys of its results may be
+// looked up by name using MatchGroupResult.Key and MatchGroupResult.KeyMap.
+type NamedKeyMatcher interface {
+	KeyMatcher
+
+	// KeyNames returns the names of the keys returned by Process, in the same
+	// order as the keys themselves.
+	KeyNames() []string
+}
+
+// NewPatternMatcher constructs a new KeyMatcher instance that uses the given
+// template pattern to match environment variable names and extract named keys
+// from those names.
+//
+// Patterns are made up of literal text and placeholders.  A placeholder is a
+// key name wrapped in curly braces, optionally followed by a colon and a regex
+// constraint that the key must satisfy.  Placeholders without a constraint
+// match one or more characters, preferring the shortest possible match.
+//
+// This function panics if the given pattern is not valid.  Use
+// ParsePatternMatcher to handle invalid patterns without panicking.
+//
+// Examples:
+//   matcher := NewPatternMatcher("host", "DB_{instance}_REPLICA_{replica}_HOST")
+//   matcher := NewPatternMatcher("host", "SERVER_{id:[0-9]+}_HOST")
+//
+// This type of matcher is useful if the wildcard parts of the target
+// environment variables are surrounded by literal text, and is a more readable
+// alternative to NewWrappedMatcher or NewRegexMatcher.
+//
+// An example of such an environment expectation might be:
+//   DB_MAIN_REPLICA_A_HOST=replica-a.example.com
+//   DB_MAIN_REPLICA_B_HOST=replica-b.example.com
+// In this example, the keys "instance" and "replica" would be "MAIN" and "A" or
+// "B".
+func NewPatternMatcher(name, pattern string) KeyMatcher {
+	if m, err := ParsePatternMatcher(name, pattern); err != nil {
+		panic(err)
+	} else {
+		return m
+	}
+}
+
+// ParsePatternMatcher constructs a new KeyMatcher instance from the given
+// template pattern, returning an error if the pattern is not valid.
+//
+// See NewPatternMatcher for a description of the pattern syntax.
+func ParsePatternMatcher(name, pattern string) (KeyMatcher, error) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &patternKeyMatcher{name: name, segments: segments}
+
+	// Peel the leading and trailing literals off the pattern so that they may be
+	// checked cheaply before falling back to the regex.
+	if !segments[0].placeholder {
+		out.prefix = segments[0].text
+		segments = segments[1:]
+	}
+	if last := len(segments) - 1; !segments[last].placeholder {
+		out.suffix = segments[last].text
+		segments = segments[:last]
+	}
+
+	out.minLen = len(out.prefix) + len(out.suffix)
+	for _, seg := range segments {
+		if seg.placeholder {
+			out.keys = append(out.keys, seg.text)
+			out.minLen++
+		} else {
+			out.minLen += len(seg.text)
+		}
+	}
+
+	// A single unconstrained placeholder can be extracted by slicing, so the
+	// regex is only needed for anything more complex.
+	if len(segments) == 1 && segments[0].constraint == "" {
+		return out, nil
+	}
+
+	sb := new(strings.Builder)
+	sb.WriteByte('^')
+	sb.WriteString(regexp.QuoteMeta(out.prefix))
+	for _, seg := range segments {
+		if !seg.placeholder {
+			sb.WriteString(regexp.QuoteMeta(seg.text))
+		} else if seg.constraint == "" {
+			sb.WriteString("(?P<" + seg.text + ">.+?)")
+		} else {
+			sb.WriteString("(?P<" + seg.text + ">(?:" + seg.constraint + "))")
+		}
+	}
+	sb.WriteString(regexp.QuoteMeta(out.suffix))
+	sb.WriteByte('$')
+
+	if out.regex, err = regexp.Compile(sb.String()); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	out.indices = make([]int, len(out.keys))
+	for i, key := range out.keys {
+		out.indices[i] = out.regex.SubexpIndex(key)
+	}
+
+	return out, nil
+}
+
+type patternKeyMatcher struct {
+	name     string
+	segments []patternSegment
+	prefix   string
+	suffix   string
+	minLen   int
+	keys     []string
+
+	// regex and indices are only set when the pattern cannot be matched using the
+	// prefix and suffix alone.
+	regex   *regexp.Regexp
+	indices []int
+}
+
+func (p *patternKeyMatcher) Name() string {
+	return p.name
+}
+
+func (p *patternKeyMatcher) KeyNames() []string {
+	return slices.Clone(p.keys)
+}
+
+func (p *patternKeyMatcher) Matches(key string) bool {
+	if len(key) < p.minLen || !strings.HasPrefix(key, p.prefix) || !strings.HasSuffix(key, p.suffix) {
+		return false
+	}
+
+	return p.regex == nil || p.regex.MatchString(key)
+}
+
+func (p *patternKeyMatcher) Process(key string) []string {
+	if p.regex == nil {
+		return []string{key[len(p.prefix) : len(key)-len(p.suffix)]}
+	}
+
+	matches := p.regex.FindStringSubmatch(key)
+
+	if len(matches) == 0 {
+		panic(fmt.Errorf("illegal state: no matches were found for key matcher %s", p.name))
+	}
+
+	out := make([]string, len(p.indices))
+	for i, idx := range p.indices {
+		out[i] = matches[idx]
+	}
+
+	return out
+}
+
+func (p *patternKeyMatcher) Render(keys []string) (string, bool) {
+	if len(keys) != len(p.keys) {
+		return "", false
+	}
+
+	sb := new(strings.Builder)
+	i := 0
+
+	for _, seg := range p.segments {
+		if seg.placeholder {
+			sb.WriteString(keys[i])
+			i++
+		} else {
+			sb.WriteString(seg.text)
+		}
+	}
+
+	if out := sb.String(); p.Matches(out) {
+		return out, true
+	}
+
+	return "", false
+}
+
+type patternSegment struct {
+	// text is the literal text for literal segments, or the key name for
+	// placeholder segments.
+	text        string
+	constraint  string
+	placeholder bool
+}
+
+func parsePattern(pattern string) ([]patternSegment, error) {
+	segments := make([]patternSegment, 0, 8)
+	names := make(map[string]bool, 4)
+	start := 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '}':
+			return nil, fmt.Errorf("invalid pattern %q: unexpected '}' at offset %d", pattern, i)
+
+		case '{':
+			// Find the matching close brace, allowing for braces used in the
+			// placeholder's constraint, such as "{id:[0-9]{2}}".
+			end, depth := -1, 1
+			for j := i + 1; j < len(pattern) && end == -1; j++ {
+				switch pattern[j] {
+				case '{':
+					depth++
+				case '}':
+					if depth--; depth == 0 {
+						end = j
+					}
+				}
+			}
+
+			if end == -1 {
+				return nil, fmt.Errorf("invalid pattern %q: unclosed placeholder at offset %d", pattern, i)
+			}
+
+			name, constraint, _ := strings.Cut(pattern[i+1:end], ":")
+
+			if !isPlaceholderName(name) {
+				return nil, fmt.Errorf("invalid pattern %q: invalid placeholder name %q", pattern, name)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("invalid pattern %q: duplicate placeholder name %q", pattern, name)
+			}
+			names[name] = true
+
+			if start < i {
+				segments = append(segments, patternSegment{text: pattern[start:i]})
+			}
+			segments = append(segments, patternSegment{text: name, constraint: constraint, placeholder: true})
+
+			i = end
+			start = end + 1
+		}
+	}
+
+	if start < len(pattern) {
+		segments = append(segments, patternSegment{text: pattern[start:]})
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("invalid pattern %q: pattern contains no placeholders", pattern)
+	}
+
+	return segments, nil
+}
+
+func isPlaceholderName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -78,6 +78,30 @@ func TestNewRegexMatcher(t *testing.T) {
 			So(func() { matcher.Process("foo") }, ShouldPanic)
 		})
 
+		Convey("with named capture groups", func() {
+			matcher := wenv.NewRegexMatcher("test", regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_REGION_(?P<region>\w+)_URL$`))
+
+			So(matcher.(wenv.NamedKeyMatcher).KeyNames(), ShouldResemble, []string{"tenant", "region"})
+			So(matcher.Process("TENANT_FOO_REGION_BAR_URL"), ShouldResemble, []string{"FOO", "BAR"})
+
+			regex := regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_URL$`)
+			names := wenv.NewRegexMatcher("test", regex).(wenv.NamedKeyMatcher).KeyNames()
+			names[0] = "changed"
+			So(regex.SubexpNames(), ShouldResemble, []string{"", "tenant"})
+		})
+
+		Convey("with partially named capture groups", func() {
+			So(func() {
+				wenv.NewRegexMatcher("test", regexp.MustCompile(`^TENANT_(?P<tenant>\w+)_REGION_(\w+)_URL$`))
+			}, ShouldPanic)
+		})
+
+		Convey("without named capture groups", func() {
+			matcher := wenv.NewRegexMatcher("test", regexp.MustCompile(`^PREFIX_(\w+)_(\w+)_SUFFIX$`))
+
+			So(matcher.(wenv.NamedKeyMatcher).KeyNames(), ShouldBeNil)
+		})
+
 		Convey("with a regex containing no matching groups", func() {
 			matcher := wenv.NewRegexMatcher("test", regexp.MustCompile(`^PREFIX_\w+_\w+_SUFFIX$`))
 
@@ -85,3 +109,97 @@ func TestNewRegexMatcher(t *testing.T) {
 		})
 	})
 }
+
+func TestNewPatternMatcher(t *testing.T) {
+	Convey("pattern matcher", t, func() {
+		Convey("with a single placeholder", func() {
+			matcher := wenv.NewPatternMatcher("test", "MY_PREFIX_{key}_MY_SUFFIX")
+
+			So(matcher.Name(), ShouldEqual, "test")
+			So(matcher.(wenv.NamedKeyMatcher).KeyNames(), ShouldResemble, []string{"key"})
+
+			So(matcher.Matches("MY_PREFIX__MY_SUFFIX"), ShouldBeFalse)
+			So(matcher.Matches("MY_PREFIX_FOO_MY_SUFFIX"), ShouldBeTrue)
+
+			So(matcher.Process("MY_PREFIX_FOO_MY_SUFFIX"), ShouldResemble, []string{"FOO"})
+		})
+
+		Convey("with multiple placeholders", func() {
+			matcher := wenv.NewPatternMatcher("test", "DB_{instance}_REPLICA_{replica}_HOST")
+
+			So(matcher.(wenv.NamedKeyMatcher).KeyNames(), ShouldResemble, []string{"instance", "replica"})
+
+			matcher.(wenv.NamedKeyMatcher).KeyNames()[0] = "changed"
+			So(matcher.(wenv.NamedKeyMatcher).KeyNames(), ShouldResemble, []string{"instance", "replica"})
+
+			So(matcher.Matches("DB_MAIN_REPLICA__HOST"), ShouldBeFalse)
+			So(matcher.Matches("DB_MAIN_HOST"), ShouldBeFalse)
+			So(matcher.Matches("DB_MAIN_REPLICA_A_HOST"), ShouldBeTrue)
+
+			So(matcher.Process("DB_MAIN_REPLICA_A_HOST"), ShouldResemble, []string{"MAIN", "A"})
+		})
+
+		Convey("with a constrained placeholder", func() {
+			matcher := wenv.NewPatternMatcher("test", "SERVER_{id:[0-9]{1,3}}_HOST")
+
+			So(matcher.Matches("SERVER_FOO_HOST"), ShouldBeFalse)
+			So(matcher.Matches("SERVER_1234_HOST"), ShouldBeFalse)
+			So(matcher.Matches("SERVER_12_HOST"), ShouldBeTrue)
+
+			So(matcher.Process("SERVER_12_HOST"), ShouldResemble, []string{"12"})
+		})
+
+		Convey("with invalid patterns", func() {
+			for _, pattern := range []string{
+				"NO_PLACEHOLDERS",
+				"UNCLOSED_{key",
+				"UNOPENED_key}",
+				"EMPTY_{}_NAME",
+				"BAD_{1key}_NAME",
+				"DUPLICATE_{key}_{key}",
+				"BAD_{key:[}_CONSTRAINT",
+			} {
+				_, err := wenv.ParsePatternMatcher("test", pattern)
+				So(err, ShouldNotBeNil)
+				So(func() { wenv.NewPatternMatcher("test", pattern) }, ShouldPanic)
+			}
+		})
+	})
+}
+
+func TestKeyRenderer(t *testing.T) {
+	Convey("KeyRenderer", t, func() {
+		cases := map[wenv.KeyMatcher]string{
+			wenv.NewPrefixMatcher("test", "MY_PREFIX_"):                                       "MY_PREFIX_FOO",
+			wenv.NewSuffixMatcher("test", "_MY_SUFFIX"):                                       "FOO_MY_SUFFIX",
+			wenv.NewWrappedMatcher("test", "MY_PREFIX_", "_MY_SUFFIX"):                        "MY_PREFIX_FOO_MY_SUFFIX",
+			wenv.NewPatternMatcher("test", "MY_{key}_SUFFIX"):                                 "MY_FOO_SUFFIX",
+			wenv.WithFileIndirection(wenv.NewPrefixMatcher("test", "P_"), wenv.FileOptions{}): "P_FOO",
+		}
+
+		for matcher, expect := range cases {
+			name, ok := matcher.(wenv.KeyRenderer).Render([]string{"FOO"})
+			So(ok, ShouldBeTrue)
+			So(name, ShouldEqual, expect)
+
+			_, ok = matcher.(wenv.KeyRenderer).Render([]string{"FOO", "BAR"})
+			So(ok, ShouldBeFalse)
+		}
+
+		Convey("with multiple or constrained placeholders", func() {
+			matcher := wenv.NewPatternMatcher("test", "DB_{instance}_REPLICA_{replica:[0-9]+}_HOST").(wenv.KeyRenderer)
+
+			name, ok := matcher.Render([]string{"MAIN", "1"})
+			So(ok, ShouldBeTrue)
+			So(name, ShouldEqual, "DB_MAIN_REPLICA_1_HOST")
+
+			_, ok = matcher.Render([]string{"MAIN", "A"})
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("with a regex matcher", func() {
+			_, ok := wenv.NewRegexMatcher("test", regexp.MustCompile(`^(\w+)$`)).(wenv.KeyRenderer)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
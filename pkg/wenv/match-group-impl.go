@@ -1,15 +1,12 @@
 package wenv
 
-import "fmt"
-
-var merger = newKeyMerger()
+import "slices"
 
 func NewMatchGroup(name string) MatchGroup {
 	return &matchGroup{
 		name:     name,
 		matchers: make([]KeyMatcher, 0, 8),
 		required: make([]bool, 0, 8),
-		results:  newMatchGroupMap(),
 	}
 }
 
@@ -20,35 +17,54 @@ func NewMatchGroup(name string) MatchGroup {
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 
 func newMatchGroupMap() (out matchGroupMap) {
-	out.mp = make(map[string]map[string]MatchResult, 8)
-	out.keys = make(map[string][]string, 8)
+	out.mp = make(map[InstanceID]map[string]MatchResult, 8)
+	out.keys = make(map[InstanceID][]string, 8)
+	out.seen = make([]InstanceID, 0, 8)
 	return
 }
 
 type matchGroupMap struct {
-	mp   map[string]map[string]MatchResult
-	keys map[string][]string
+	mp   map[InstanceID]map[string]MatchResult
+	keys map[InstanceID][]string
+
+	// seen contains the IDs of the instances in the order they were first seen.
+	seen []InstanceID
+
+	// errors contains the errors encountered while processing variables, such
+	// as *AmbiguousMatchError values.
+	errors []error
+
+	// children contains the state maps for the group's children.  Child state
+	// maps are shared by every instance of the group.
+	children []*matchGroupMap
+
+	// owners maps the IDs of parent instances to the IDs of the instances in
+	// this state map that belong to them.  Only set for the state maps of child
+	// groups, once they have been assigned (see assignChildren).
+	owners map[InstanceID][]InstanceID
 }
 
-func (m *matchGroupMap) put(keys []string, matcherName string, result MatchResult) {
-	mergedKey := merger.merge(keys)
+func (m *matchGroupMap) put(keys []string, matcherName string, result *matchResult) {
+	id := NewInstanceID(keys...)
 
-	m.keys[mergedKey] = keys
+	m.keys[id] = keys
+
+	if mp, ok := m.mp[id]; ok {
+		// Values given directly take precedence over values given through file
+		// indirection.
+		if prev, ok := mp[matcherName]; ok && !prev.(*matchResult).indirect && result.indirect {
+			return
+		}
 
-	if mp, ok := m.mp[mergedKey]; ok {
 		mp[matcherName] = result
 	} else {
 		mp := make(map[string]MatchResult, 8)
 		mp[matcherName] = result
-		m.mp[mergedKey] = mp
+		m.mp[id] = mp
+		m.seen = append(m.seen, id)
 	}
 }
 
-func (m *matchGroupMap) release() {
-	m.mp = nil
-	m.keys = nil
-}
-
 // // // // // // // // // // // // // // // // // // // // // // // // // // //
 //
 //    Match Group
@@ -60,9 +76,23 @@ type matchGroup struct {
 	matchers []KeyMatcher
 	required []bool
 
-	// results is a map of merged keys to maps of KeyMatcher names to match
-	// results.
-	results matchGroupMap
+	// files holds the file indirection options applied to every KeyMatcher in
+	// the group, if enabled.
+	files *FileOptions
+
+	namespaces []string
+	overlap    OverlapPolicy
+	priority   int
+	order      ResultOrder
+	compare    func(a, b MatchGroupResult) int
+
+	// indexed holds the list options for the group, if it is indexed.
+	indexed *IndexOptions
+
+	children      []MatchGroup
+	childRequired []bool
+
+	secrets []string
 }
 
 func (m *matchGroup) Name() string {
@@ -75,46 +105,433 @@ func (m *matchGroup) AddMatcher(matcher KeyMatcher, required bool) MatchGroup {
 	return m
 }
 
-func (m *matchGroup) process(key, val string) (matched bool) {
-	for _, km := range m.matchers {
-		if km.Matches(key) {
-			m.results.put(km.Process(key), km.Name(), &matchResult{key, val})
-			matched = true
+func (m *matchGroup) SetFileIndirection(options FileOptions) MatchGroup {
+	m.files = &options
+	return m
+}
+
+func (m *matchGroup) AddNamespace(prefix string) MatchGroup {
+	m.namespaces = append(m.namespaces, prefix)
+	return m
+}
+
+func (m *matchGroup) SetOverlapPolicy(policy OverlapPolicy) MatchGroup {
+	m.overlap = policy
+	return m
+}
+
+func (m *matchGroup) SetPriority(priority int) MatchGroup {
+	m.priority = priority
+	return m
+}
+
+func (m *matchGroup) SetOrder(order ResultOrder) MatchGroup {
+	m.order = order
+	m.compare = nil
+	return m
+}
+
+func (m *matchGroup) SetOrderFunc(compare func(a, b MatchGroupResult) int) MatchGroup {
+	m.compare = compare
+	return m
+}
+
+func (m *matchGroup) SetIndexed(options IndexOptions) MatchGroup {
+	m.indexed = &options
+	return m
+}
+
+func (m *matchGroup) AddChild(child MatchGroup, required bool) MatchGroup {
+	m.children = append(m.children, child)
+	m.childRequired = append(m.childRequired, required)
+	return m
+}
+
+func (m *matchGroup) SetSecret(matcherNames ...string) MatchGroup {
+	m.secrets = append(m.secrets, matcherNames...)
+	return m
+}
+
+func (m *matchGroup) compile() (*compiledMatchGroup, error) {
+	out := &compiledMatchGroup{
+		name:     m.name,
+		matchers: make([]KeyMatcher, len(m.matchers)),
+		required: make([]bool, len(m.required)),
+		overlap:  m.overlap,
+		priority: m.priority,
+		compare:  m.compare,
+	}
+
+	if m.indexed != nil {
+		indexed := *m.indexed
+		out.indexed = &indexed
+	}
+
+	if len(m.secrets) > 0 {
+		out.secrets = make(map[string]bool, len(m.secrets))
+		for _, name := range m.secrets {
+			// A misspelled name would silently leave the value it was meant to
+			// protect exposed.
+			if !slices.ContainsFunc(m.matchers, func(km KeyMatcher) bool { return km.Name() == name }) {
+				return nil, &SecretNameError{Group: m.name, Matcher: name}
+			}
+
+			out.secrets[name] = true
 		}
 	}
 
-	return
-}
+	if out.compare == nil {
+		out.compare = m.order.compareFunc()
+	}
 
-func (m *matchGroup) result() (MatchGroupResults, []error) {
-	results := make([]MatchGroupResult, 0, len(m.results.mp))
+	copy(out.matchers, m.matchers)
+	copy(out.required, m.required)
 
-	for mergedKey, keyMatchers := range m.results.mp {
-		keys := m.results.keys[mergedKey]
-		results = append(results, newMatchGroupResult(m.name, keys, keyMatchers))
+	if len(m.namespaces) > 0 {
+		out.namespaces = make([]string, len(m.namespaces))
+		copy(out.namespaces, m.namespaces)
 	}
 
-	errors := make([]error, 0, 8)
+	if m.files != nil {
+		for i, km := range out.matchers {
+			out.matchers[i] = WithFileIndirection(km, *m.files)
+		}
+	}
+
+	// Every KeyMatcher in the group that is able to name its keys must agree on
+	// what those names are, otherwise lookups by key name would be ambiguous.
+	var namedBy string
+	for _, km := range out.matchers {
+		nkm, ok := km.(NamedKeyMatcher)
+		if !ok {
+			continue
+		}
+
+		names := nkm.KeyNames()
+		if names == nil {
+			continue
+		}
+
+		if out.keyNames == nil {
+			out.keyNames = slices.Clone(names)
+			namedBy = km.Name()
+		} else if !slices.Equal(out.keyNames, names) {
+			return nil, &KeyNameError{
+				Group:            m.name,
+				Matcher:          km.Name(),
+				KeyNames:         names,
+				Expected:         namedBy,
+				ExpectedKeyNames: out.keyNames,
+			}
+		}
+	}
+
+	for i, child := range m.children {
+		cg, err := child.compile()
+		if err != nil {
+			return nil, err
+		}
 
-	// Iterate through all the keys
-	for i, req := range m.required {
-		// filter down to only those that are required
-		if req {
-			// iterate through the result groups
-			for _, res := range results {
-				// If the result doesn't have a match for the required key
-				if !res.Has(m.matchers[i].Name()) {
-					errors = append(errors, fmt.Errorf("match group %s (keys: %s) does not have a match for required key %s", m.name, merger.merge(res.Keys()), m.matchers[i].Name()))
+		out.children = append(out.children, cg)
+		out.childRequired = append(out.childRequired, m.childRequired[i])
+		out.warnings = append(out.warnings, cg.warnings...)
+		out.namespaces = append(out.namespaces, cg.namespaces...)
+	}
+
+	// all lists every KeyMatcher of the group, preceded by the marker for its
+	// children if it has any.
+	if len(out.children) > 0 {
+		out.all = append(out.all, childMarker)
+	}
+	for i := range out.matchers {
+		out.all = append(out.all, i)
+	}
+
+	// Overlapping KeyMatchers are only worth warning about if the group does not
+	// resolve them deterministically.
+	if out.overlap == OverlapAll || out.overlap == OverlapReject {
+		for i, a := range out.matchers {
+			for _, b := range out.matchers[:i] {
+				if canOverlap(a, b) {
+					out.warnings = append(out.warnings, &OverlapError{m.name, a.Name(), b.Name()})
 				}
 			}
 		}
 	}
 
+	return out, nil
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Compiled Match Group
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// compiledMatchGroup is an immutable copy of a MatchGroup's configuration.
+//
+// A compiledMatchGroup holds no per-parse state of its own; all intermediate
+// results are kept in a matchGroupMap owned by the caller, which allows a single
+// compiledMatchGroup to be used by multiple goroutines at once.
+type compiledMatchGroup struct {
+	name     string
+	matchers []KeyMatcher
+	required []bool
+
+	// keyNames contains the key names shared by all the NamedKeyMatchers in the
+	// group.  If the group contains no NamedKeyMatchers, this will be nil.
+	keyNames []string
+
+	// namespaces contains the variable name prefixes claimed by the group.
+	namespaces []string
+	overlap    OverlapPolicy
+	priority   int
+
+	// compare orders the instances in the group's results.  If nil, instances
+	// are kept in the order they were first seen.
+	compare func(a, b MatchGroupResult) int
+
+	// indexed holds the list options for the group, if it is indexed.
+	indexed *IndexOptions
+
+	children      []*compiledMatchGroup
+	childRequired []bool
+
+	// secrets contains the names of the KeyMatchers whose values are redacted
+	// when serialized.
+	secrets map[string]bool
+
+	// all contains the candidate indices for every KeyMatcher of the group (see
+	// process).
+	all []int
+
+	// warnings contains the problems found while compiling the group that do
+	// not prevent it from being used.
+	warnings []error
+}
+
+// process processes the given environment variable against the KeyMatchers at
+// the given indices, recording any hits in the given state map.
+//
+// The indices must be in ascending order.  If the indices start with
+// childMarker, the variable is first offered to the group's children, and is
+// only offered to the group's own KeyMatchers if no child matched it.
+func (m *compiledMatchGroup) process(state *matchGroupMap, v Variable, candidates []int) (matched bool) {
+	if len(candidates) > 0 && candidates[0] == childMarker {
+		if m.processChildren(state, v) {
+			return true
+		}
+
+		candidates = candidates[1:]
+	}
+
+	if m.overlap == OverlapAll {
+		for _, i := range candidates {
+			if km := m.matchers[i]; km.Matches(v.Name) {
+				m.apply(state, km, km.Process(v.Name), v)
+				matched = true
+			}
+		}
+
+		return
+	}
+
+	var hits []KeyMatcher
+	for _, i := range candidates {
+		if km := m.matchers[i]; km.Matches(v.Name) {
+			hits = append(hits, km)
+		}
+	}
+
+	switch {
+	case len(hits) == 0:
+		return false
+	case len(hits) == 1 || m.overlap == OverlapFirst:
+		m.apply(state, hits[0], hits[0].Process(v.Name), v)
+	case m.overlap == OverlapLongest:
+		best, bestKeys := hits[0], hits[0].Process(v.Name)
+		for _, km := range hits[1:] {
+			if keys := km.Process(v.Name); literalLength(v.Name, keys) > literalLength(v.Name, bestKeys) {
+				best, bestKeys = km, keys
+			}
+		}
+		m.apply(state, best, bestKeys, v)
+	default:
+		names := make([]string, len(hits))
+		for i, km := range hits {
+			names[i] = km.Name()
+		}
+		state.errors = append(state.errors, &AmbiguousMatchError{m.name, v.Name, names})
+	}
+
+	return true
+}
+
+// apply records a hit for the given KeyMatcher and keys in the given state map.
+func (m *compiledMatchGroup) apply(state *matchGroupMap, km KeyMatcher, keys []string, v Variable) {
+	res := &matchResult{raw: v.Name, value: v.Value, origin: v.Origin}
+
+	// The file for an indirect value is only read once the group's results are
+	// collected, and only if no direct value was found for the key.
+	if fm, ok := km.(*fileKeyMatcher); ok && fm.indirect(v.Name) {
+		res.indirect, res.file = true, v.Value
+	}
+
+	state.put(keys, km.Name(), res)
+}
+
+// result returns the processing results recorded in the given state map for
+// the instances with the given IDs.
+//
+// The results, and the errors for each, are returned in the group's configured
+// order.  For child groups, offset is the number of keys belonging to the
+// parent instance.
+func (m *compiledMatchGroup) result(state *matchGroupMap, ids []InstanceID, offset int) (MatchGroupResults, []error) {
+	instances := make([]groupInstance, 0, len(ids))
+
+	for _, id := range ids {
+		keyMatchers := state.mp[id]
+		keys := state.keys[id]
+		errors := make([]error, 0, 2)
+
+		// Read the values for any keys matched through file indirection.  Keys
+		// whose files could not be read are removed from the results.
+		failed := m.readFiles(keys, keyMatchers, &errors)
+
+		if len(keyMatchers) == 0 {
+			instances = append(instances, groupInstance{nil, errors})
+			continue
+		}
+
+		res := newMatchGroupResult(m, id, keys, keyMatchers)
+
+		// Iterate through all the keys
+		for i, req := range m.required {
+			name := m.matchers[i].Name()
+
+			// If the key is required and the result doesn't have a match for it
+			// (and it wasn't already reported as a file error)
+			if req && !res.Has(name) && !failed[name] {
+				errors = append(errors, &MissingKeyError{
+					Group:    m.name,
+					Keys:     keys,
+					Matcher:  name,
+					Variable: m.expectedName(name, keys),
+					Required: true,
+				})
+			}
+		}
+
+		instances = append(instances, groupInstance{res, errors})
+	}
+
+	var listErrors []error
+
+	if m.indexed != nil {
+		instances, listErrors = m.indexInstances(instances, offset)
+	} else if m.compare != nil {
+		slices.SortStableFunc(instances, func(a, b groupInstance) int {
+			// Instances without a result only carry errors, and are sorted last.
+			switch {
+			case a.result == nil && b.result == nil:
+				return 0
+			case a.result == nil:
+				return 1
+			case b.result == nil:
+				return -1
+			default:
+				return m.compare(a.result, b.result)
+			}
+		})
+	}
+
+	if len(m.children) > 0 {
+		listErrors = append(listErrors, m.childResults(state, instances)...)
+	}
+
+	results := make([]MatchGroupResult, 0, len(instances))
+	errors := make([]error, 0, 8)
+
+	for _, inst := range instances {
+		if inst.result != nil {
+			results = append(results, inst.result)
+		}
+		errors = append(errors, inst.errors...)
+	}
+
+	errors = append(errors, listErrors...)
+
 	return matchGroupResults(results), errors
 }
 
-func (m *matchGroup) release() {
-	m.matchers = nil
-	m.required = nil
-	m.results.release()
+// groupInstance holds the result for a single instance of a group along with
+// the errors found for it, so that the errors may be ordered alongside the
+// results.
+type groupInstance struct {
+	result *matchGroupResult
+	errors []error
+}
+
+// expectedName renders the environment variable name the named KeyMatcher
+// expects for the given keys, or returns an empty string if the KeyMatcher
+// does not exist or cannot render names.
+func (m *compiledMatchGroup) expectedName(matcherName string, keys []string) string {
+	for _, km := range m.matchers {
+		if km.Name() != matcherName {
+			continue
+		}
+
+		if kr, ok := km.(KeyRenderer); ok {
+			if name, ok := kr.Render(keys); ok {
+				return name
+			}
+		}
+
+		break
+	}
+
+	return ""
+}
+
+// readFiles reads the values for the given results that were matched through
+// file indirection, removing the results whose files could not be read and
+// appending a *FileError for each to the given errors.
+//
+// Returns the names of the KeyMatchers whose results were removed.
+func (m *compiledMatchGroup) readFiles(keys []string, results map[string]MatchResult, errors *[]error) (failed map[string]bool) {
+	for _, km := range m.matchers {
+		fm, ok := km.(*fileKeyMatcher)
+		if !ok {
+			continue
+		}
+
+		res, ok := results[km.Name()].(*matchResult)
+		if !ok || !res.indirect {
+			continue
+		}
+
+		value, err := fm.readFile(res.file)
+		if err == nil {
+			res.value = value
+			continue
+		}
+
+		*errors = append(*errors, &FileError{
+			Group:    m.name,
+			Keys:     keys,
+			Matcher:  km.Name(),
+			Variable: res.raw,
+			Path:     res.file,
+			Err:      err,
+		})
+
+		delete(results, km.Name())
+
+		if failed == nil {
+			failed = make(map[string]bool, 2)
+		}
+		failed[km.Name()] = true
+	}
+
+	return
 }
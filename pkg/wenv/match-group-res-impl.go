@@ -1,5 +1,12 @@
 package wenv
 
+import (
+	"iter"
+	"net"
+	"net/url"
+	"time"
+)
+
 type matchGroupResults []MatchGroupResult
 
 func (m matchGroupResults) Size() int {
@@ -10,18 +17,40 @@ func (m matchGroupResults) Get(index int) MatchGroupResult {
 	return m[index]
 }
 
-func newMatchGroupResult(name string, keys []string, results map[string]MatchResult) MatchGroupResult {
+func (m matchGroupResults) All() iter.Seq2[int, MatchGroupResult] {
+	return func(yield func(int, MatchGroupResult) bool) {
+		for i, res := range m {
+			if !yield(i, res) {
+				return
+			}
+		}
+	}
+}
+
+func newMatchGroupResult(group *compiledMatchGroup, id InstanceID, keys []string, results map[string]MatchResult) *matchGroupResult {
 	return &matchGroupResult{
-		results: results,
-		name:    name,
-		keys:    keys,
+		results:  results,
+		group:    group,
+		name:     group.name,
+		id:       id,
+		keys:     keys,
+		keyNames: group.keyNames,
 	}
 }
 
 type matchGroupResult struct {
-	results map[string]MatchResult
-	name    string
-	keys    []string
+	results  map[string]MatchResult
+	group    *compiledMatchGroup
+	name     string
+	id       InstanceID
+	keys     []string
+	keyNames []string
+
+	// index is the list index of the instance, if its group is indexed.
+	index   int
+	indexed bool
+
+	children map[string]MatchGroupResults
 }
 
 func (m *matchGroupResult) Size() int {
@@ -32,6 +61,21 @@ func (m *matchGroupResult) Name() string {
 	return m.name
 }
 
+func (m *matchGroupResult) ID() InstanceID {
+	return m.id
+}
+
+func (m *matchGroupResult) Index() (int, bool) {
+	return m.index, m.indexed
+}
+
+func (m *matchGroupResult) Children(groupName string) MatchGroupResults {
+	if res, ok := m.children[groupName]; ok {
+		return res
+	}
+	return nil
+}
+
 func (m *matchGroupResult) Keys() []string {
 	return m.keys
 }
@@ -40,6 +84,35 @@ func (m *matchGroupResult) FirstKey() string {
 	return m.keys[0]
 }
 
+func (m *matchGroupResult) Key(keyName string) string {
+	for i, name := range m.keyNames {
+		if name != "" && name == keyName && i < len(m.keys) {
+			return m.keys[i]
+		}
+	}
+
+	return ""
+}
+
+func (m *matchGroupResult) KeyMap() map[string]string {
+	if m.keyNames == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(m.keyNames))
+	for i, name := range m.keyNames {
+		if name != "" && i < len(m.keys) {
+			out[name] = m.keys[i]
+		}
+	}
+
+	return out
+}
+
+func (m *matchGroupResult) ExpectedName(matcherName string) string {
+	return m.group.expectedName(matcherName, m.keys)
+}
+
 func (m *matchGroupResult) Has(matcherName string) bool {
 	_, ok := m.results[matcherName]
 	return ok
@@ -49,6 +122,28 @@ func (m *matchGroupResult) Get(matcherName string) MatchResult {
 	return m.results[matcherName]
 }
 
+func (m *matchGroupResult) Names() []string {
+	out := make([]string, 0, len(m.results))
+
+	for _, km := range m.group.matchers {
+		if _, ok := m.results[km.Name()]; ok {
+			out = append(out, km.Name())
+		}
+	}
+
+	return out
+}
+
+func (m *matchGroupResult) Matches() iter.Seq2[string, MatchResult] {
+	return func(yield func(string, MatchResult) bool) {
+		for _, name := range m.Names() {
+			if !yield(name, m.results[name]) {
+				return
+			}
+		}
+	}
+}
+
 func (m *matchGroupResult) Value(matcherName string) string {
 	return m.results[matcherName].Value()
 }
@@ -60,3 +155,153 @@ func (m *matchGroupResult) ValueOr(matcherName, fallback string) string {
 		return fallback
 	}
 }
+
+func (m *matchGroupResult) Int(matcherName string) (int, error) {
+	return convertMatch(m, matcherName, "int", parseInt)
+}
+
+func (m *matchGroupResult) IntOr(matcherName string, fallback int) int {
+	return convertMatchOr(m, matcherName, fallback, parseInt)
+}
+
+func (m *matchGroupResult) Int64(matcherName string) (int64, error) {
+	return convertMatch(m, matcherName, "int64", parseInt64)
+}
+
+func (m *matchGroupResult) Int64Or(matcherName string, fallback int64) int64 {
+	return convertMatchOr(m, matcherName, fallback, parseInt64)
+}
+
+func (m *matchGroupResult) Uint(matcherName string) (uint, error) {
+	return convertMatch(m, matcherName, "uint", parseUint)
+}
+
+func (m *matchGroupResult) UintOr(matcherName string, fallback uint) uint {
+	return convertMatchOr(m, matcherName, fallback, parseUint)
+}
+
+func (m *matchGroupResult) Float(matcherName string) (float64, error) {
+	return convertMatch(m, matcherName, "float64", parseFloat)
+}
+
+func (m *matchGroupResult) FloatOr(matcherName string, fallback float64) float64 {
+	return convertMatchOr(m, matcherName, fallback, parseFloat)
+}
+
+func (m *matchGroupResult) Bool(matcherName string) (bool, error) {
+	return convertMatch(m, matcherName, "bool", parseBool)
+}
+
+func (m *matchGroupResult) BoolOr(matcherName string, fallback bool) bool {
+	return convertMatchOr(m, matcherName, fallback, parseBool)
+}
+
+func (m *matchGroupResult) Duration(matcherName string) (time.Duration, error) {
+	return convertMatch(m, matcherName, "time.Duration", parseDuration)
+}
+
+func (m *matchGroupResult) DurationOr(matcherName string, fallback time.Duration) time.Duration {
+	return convertMatchOr(m, matcherName, fallback, parseDuration)
+}
+
+func (m *matchGroupResult) Time(matcherName, layout string) (time.Time, error) {
+	return convertMatch(m, matcherName, "time.Time", timeParser(layout))
+}
+
+func (m *matchGroupResult) TimeOr(matcherName, layout string, fallback time.Time) time.Time {
+	return convertMatchOr(m, matcherName, fallback, timeParser(layout))
+}
+
+func (m *matchGroupResult) URL(matcherName string) (*url.URL, error) {
+	return convertMatch(m, matcherName, "URL", parseURL)
+}
+
+func (m *matchGroupResult) URLOr(matcherName string, fallback *url.URL) *url.URL {
+	return convertMatchOr(m, matcherName, fallback, parseURL)
+}
+
+func (m *matchGroupResult) IP(matcherName string) (net.IP, error) {
+	return convertMatch(m, matcherName, "IP", parseIP)
+}
+
+func (m *matchGroupResult) IPOr(matcherName string, fallback net.IP) net.IP {
+	return convertMatchOr(m, matcherName, fallback, parseIP)
+}
+
+func (m *matchGroupResult) CIDR(matcherName string) (*net.IPNet, error) {
+	return convertMatch(m, matcherName, "CIDR", parseCIDR)
+}
+
+func (m *matchGroupResult) CIDROr(matcherName string, fallback *net.IPNet) *net.IPNet {
+	return convertMatchOr(m, matcherName, fallback, parseCIDR)
+}
+
+func (m *matchGroupResult) Bytes(matcherName string) (uint64, error) {
+	return convertMatch(m, matcherName, "byte size", parseBytes)
+}
+
+func (m *matchGroupResult) BytesOr(matcherName string, fallback uint64) uint64 {
+	return convertMatchOr(m, matcherName, fallback, parseBytes)
+}
+
+func (m *matchGroupResult) StringSlice(matcherName, sep string) ([]string, error) {
+	return convertMatch(m, matcherName, "[]string", stringSliceParser(sep))
+}
+
+func (m *matchGroupResult) StringSliceOr(matcherName, sep string, fallback []string) []string {
+	return convertMatchOr(m, matcherName, fallback, stringSliceParser(sep))
+}
+
+// convertMatch parses the value matched by the named KeyMatcher using the given
+// parse function, wrapping any failure in a *ConversionError.
+func convertMatch[T any](m *matchGroupResult, matcherName, typeName string, parse func(string) (T, error)) (out T, err error) {
+	res, ok := m.results[matcherName]
+	if !ok {
+		err = &MissingKeyError{
+			Group:    m.name,
+			Keys:     m.keys,
+			Matcher:  matcherName,
+			Variable: m.ExpectedName(matcherName),
+		}
+		return
+	}
+
+	if out, err = parse(res.Value()); err != nil {
+		err = (&ConversionError{
+			Group:    m.name,
+			Keys:     m.keys,
+			Matcher:  matcherName,
+			Variable: res.Raw(),
+			Value:    res.Value(),
+			Secret:   isSecret(m, matcherName),
+			Type:     typeName,
+			Err:      err,
+		}).redact()
+	}
+
+	return
+}
+
+// convertMatchOr parses the value matched by the named KeyMatcher using the
+// given parse function, returning the fallback value on failure.
+func convertMatchOr[T any](m *matchGroupResult, matcherName string, fallback T, parse func(string) (T, error)) T {
+	if res, ok := m.results[matcherName]; ok {
+		if out, err := parse(res.Value()); err == nil {
+			return out
+		}
+	}
+
+	return fallback
+}
+
+func timeParser(layout string) func(string) (time.Time, error) {
+	return func(value string) (time.Time, error) {
+		return time.Parse(layout, value)
+	}
+}
+
+func stringSliceParser(sep string) func(string) ([]string, error) {
+	return func(value string) ([]string, error) {
+		return parseStringSlice(value, sep), nil
+	}
+}
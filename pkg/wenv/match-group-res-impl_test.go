@@ -0,0 +1,177 @@
+package wenv_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestMatchGroupResultTypedAccessors(t *testing.T) {
+	Convey("MatchGroupResult typed accessors", t, func() {
+		group := wenv.NewMatchGroup("app")
+		for _, name := range []string{"int", "uint", "float", "bool", "duration", "time", "url", "ip", "cidr", "bytes", "list", "bad"} {
+			group.AddMatcher(wenv.NewPrefixMatcher(name, name+"_"), false)
+		}
+
+		res := wenv.NewEnvironmentMatcher().
+			AddGroup(group, true).
+			ParseEnv(map[string]string{
+				"int_FOO":      "-42",
+				"uint_FOO":     "42",
+				"float_FOO":    "4.2",
+				"bool_FOO":     "yes",
+				"duration_FOO": "1m30s",
+				"time_FOO":     "2024-01-02",
+				"url_FOO":      "https://example.com/path",
+				"ip_FOO":       "::1",
+				"cidr_FOO":     "10.0.0.0/8",
+				"bytes_FOO":    "512MiB",
+				"list_FOO":     "a, b ,c",
+				"bad_FOO":      "not a number",
+			}).
+			Get("app").
+			Get(0)
+
+		Convey("parse valid values", func() {
+			i, err := res.Int("int")
+			So(err, ShouldBeNil)
+			So(i, ShouldEqual, -42)
+
+			i64, err := res.Int64("int")
+			So(err, ShouldBeNil)
+			So(i64, ShouldEqual, -42)
+
+			u, err := res.Uint("uint")
+			So(err, ShouldBeNil)
+			So(u, ShouldEqual, 42)
+
+			f, err := res.Float("float")
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, 4.2)
+
+			b, err := res.Bool("bool")
+			So(err, ShouldBeNil)
+			So(b, ShouldBeTrue)
+
+			d, err := res.Duration("duration")
+			So(err, ShouldBeNil)
+			So(d, ShouldEqual, 90*time.Second)
+
+			tm, err := res.Time("time", time.DateOnly)
+			So(err, ShouldBeNil)
+			So(tm.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+
+			ur, err := res.URL("url")
+			So(err, ShouldBeNil)
+			So(ur.Host, ShouldEqual, "example.com")
+
+			ip, err := res.IP("ip")
+			So(err, ShouldBeNil)
+			So(ip.Equal(net.IPv6loopback), ShouldBeTrue)
+
+			cidr, err := res.CIDR("cidr")
+			So(err, ShouldBeNil)
+			So(cidr.String(), ShouldEqual, "10.0.0.0/8")
+
+			by, err := res.Bytes("bytes")
+			So(err, ShouldBeNil)
+			So(by, ShouldEqual, 512<<20)
+
+			list, err := res.StringSlice("list", ",")
+			So(err, ShouldBeNil)
+			So(list, ShouldResemble, []string{"a", "b", "c"})
+		})
+
+		Convey("report conversion errors", func() {
+			_, err := res.Int("bad")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, `match group app (keys: FOO): cannot convert bad_FOO ("not a number") to int: `)
+
+			var convErr *wenv.ConversionError
+			So(errors.As(err, &convErr), ShouldBeTrue)
+			So(convErr.Group, ShouldEqual, "app")
+			So(convErr.Keys, ShouldResemble, []string{"FOO"})
+			So(convErr.Matcher, ShouldEqual, "bad")
+			So(convErr.Variable, ShouldEqual, "bad_FOO")
+
+			_, err = res.Bool("bad")
+			So(err, ShouldHaveSameTypeAs, convErr)
+			_, err = res.Bytes("bad")
+			So(err, ShouldHaveSameTypeAs, convErr)
+			_, err = res.IP("bad")
+			So(err, ShouldHaveSameTypeAs, convErr)
+		})
+
+		Convey("report missing keys", func() {
+			_, err := res.Int("missing")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "match group app (keys: FOO) does not have a match for key missing")
+		})
+
+		Convey("return fallbacks", func() {
+			fallbackURL := &url.URL{Host: "fallback"}
+
+			So(res.IntOr("int", 1), ShouldEqual, -42)
+			So(res.IntOr("bad", 1), ShouldEqual, 1)
+			So(res.IntOr("missing", 1), ShouldEqual, 1)
+			So(res.Int64Or("bad", 2), ShouldEqual, 2)
+			So(res.UintOr("bad", 3), ShouldEqual, 3)
+			So(res.FloatOr("bad", 4.5), ShouldEqual, 4.5)
+			So(res.BoolOr("bad", true), ShouldBeTrue)
+			So(res.DurationOr("bad", time.Hour), ShouldEqual, time.Hour)
+			So(res.TimeOr("bad", time.DateOnly, time.Time{}).IsZero(), ShouldBeTrue)
+			So(res.URLOr("missing", fallbackURL), ShouldEqual, fallbackURL)
+			So(res.IPOr("bad", net.IPv4zero), ShouldEqual, net.IPv4zero)
+			So(res.CIDROr("bad", nil), ShouldBeNil)
+			So(res.BytesOr("bad", 7), ShouldEqual, 7)
+			So(res.StringSliceOr("missing", ",", []string{"x"}), ShouldResemble, []string{"x"})
+		})
+	})
+}
+
+func TestMatchGroupResultBytes(t *testing.T) {
+	Convey("MatchGroupResult.Bytes", t, func() {
+		cases := map[string]uint64{
+			"512":     512,
+			"1b":      1,
+			"64KB":    64000,
+			"64kib":   64 << 10,
+			"64K":     64 << 10,
+			"1.5GiB":  3 << 29,
+			"10 MB":   10000000,
+			"2TiB":    2 << 40,
+			"1PB":     1000000000000000,
+			" 3 mib ": 3 << 20,
+		}
+
+		for value, expect := range cases {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("app").AddMatcher(wenv.NewPrefixMatcher("size", "SIZE_"), true), true).
+				ParseEnv(map[string]string{"SIZE_FOO": value}).
+				Get("app").
+				Get(0)
+
+			size, err := res.Bytes("size")
+			So(err, ShouldBeNil)
+			So(size, ShouldEqual, expect)
+		}
+
+		for _, value := range []string{"", "MiB", "12 parsecs", "1.2.3KB", "99999999999EiB", "100000PiB"} {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("app").AddMatcher(wenv.NewPrefixMatcher("size", "SIZE_"), true), true).
+				ParseEnv(map[string]string{"SIZE_FOO": value}).
+				Get("app")
+
+			if res != nil {
+				_, err := res.Get(0).Bytes("size")
+				So(err, ShouldNotBeNil)
+			}
+		}
+	})
+}
@@ -1,5 +1,13 @@
 package wenv
 
+import (
+	"encoding/json"
+	"iter"
+	"net"
+	"net/url"
+	"time"
+)
+
 // MatchGroupResults is a list of MatchGroupResult elements for all distinct
 // MatchGroup key matches.
 //
@@ -11,17 +19,34 @@ package wenv
 //
 // the MatchGroupResults list would contain 2 MatchGroupResult elements, one for
 // the distinct key "FOO" and one for the distinct key "BAR".
+//
+// MatchGroupResults serialize to a JSON list of instances; see EnvMatchResult.
 type MatchGroupResults interface {
+	json.Marshaler
+
 	// Size returns the count of result groups in this MatchGroupResults list.
 	Size() int
 
 	// Get returns the MatchGroupResult at the given index.
 	Get(index int) MatchGroupResult
+
+	// All returns an iterator over the index and MatchGroupResult of each
+	// element in this MatchGroupResults list, in order.
+	//
+	// Example:
+	//   for _, instance := range result.Get("db").All() {
+	//     fmt.Println(instance.FirstKey())
+	//   }
+	All() iter.Seq2[int, MatchGroupResult]
 }
 
 // MatchGroupResult represents the match results for a single instance of a
 // group match.
+//
+// A MatchGroupResult serializes to a single JSON instance; see EnvMatchResult.
 type MatchGroupResult interface {
+	json.Marshaler
+
 	// Size returns the number of matched keys in this MatchGroupResult.
 	Size() int
 
@@ -29,12 +54,71 @@ type MatchGroupResult interface {
 	// MatchGroupResult.
 	Name() string
 
+	// ID returns the InstanceID identifying this MatchGroupResult within its
+	// MatchGroupResults.
+	//
+	// IDs are stable between parses of the same environment, and may be used as
+	// map keys.
+	ID() InstanceID
+
+	// Index returns the list index of this MatchGroupResult.
+	//
+	// If the MatchGroup is not indexed (see MatchGroup.SetIndexed), this method
+	// will return false.
+	Index() (int, bool)
+
+	// Children returns the results of the named child MatchGroup belonging to
+	// this MatchGroupResult (see MatchGroup.AddChild).
+	//
+	// The keys of each child result begin with the keys of this
+	// MatchGroupResult.  If there are no child results for the named group,
+	// this method will return nil.
+	//
+	// Example:
+	//   // Given the variables DB_MAIN_REPLICA_A_HOST and DB_MAIN_REPLICA_B_HOST:
+	//   replicas := result.Children("replicas")
+	//   replicas.Get(0).Key("replica") // A
+	//   replicas.Get(1).Key("replica") // B
+	Children(groupName string) MatchGroupResults
+
 	// Keys returns the distinct keys found for this MatchGroupResult.
 	Keys() []string
 
 	// FirstKey returns the first key from the Keys for this MatchGroupResult.
 	FirstKey() string
 
+	// Key returns the key with the given name.
+	//
+	// Key names are provided by NamedKeyMatcher instances such as those created
+	// by NewPatternMatcher.  If no key with the given name exists, this method
+	// will return an empty string.
+	//
+	// Example:
+	//   // Given the pattern "DB_{instance}_REPLICA_{replica}_HOST" and the
+	//   // variable DB_MAIN_REPLICA_A_HOST:
+	//   result.Key("instance") // MAIN
+	//   result.Key("replica")  // A
+	Key(keyName string) string
+
+	// KeyMap returns a map of key names to the keys found for this
+	// MatchGroupResult.
+	//
+	// If none of the KeyMatchers that hit for this MatchGroupResult were able to
+	// name their keys, this method will return nil.
+	KeyMap() map[string]string
+
+	// ExpectedName returns the name of the environment variable the named
+	// KeyMatcher would match for the keys of this MatchGroupResult.
+	//
+	// If the named KeyMatcher does not exist in the MatchGroup, or is not able
+	// to render variable names (see KeyRenderer), this method will return an
+	// empty string.
+	//
+	// Example:
+	//   // Given the KeyMatcher NewWrappedMatcher("port", "DB_", "_PORT"):
+	//   result.ExpectedName("port") // DB_FOO_PORT
+	ExpectedName(matcherName string) string
+
 	// Has tests whether this MatchGroupResult contains a result for the target
 	// KeyMatcher name.
 	Has(matcherName string) bool
@@ -42,6 +126,21 @@ type MatchGroupResult interface {
 	// Get returns the MatchResult for the target KeyMatcher name.
 	Get(matcherName string) MatchResult
 
+	// Names returns the names of the KeyMatchers that have a result in this
+	// MatchGroupResult, in the order the KeyMatchers were added to the
+	// MatchGroup.
+	Names() []string
+
+	// Matches returns an iterator over the KeyMatchers that have a result in
+	// this MatchGroupResult, yielding the name and MatchResult of each in the
+	// same order as Names.
+	//
+	// Example:
+	//   for name, match := range instance.Matches() {
+	//     fmt.Printf("%s=%s (from %s)\n", name, match.Value(), match.Raw())
+	//   }
+	Matches() iter.Seq2[string, MatchResult]
+
 	// Value returns the environment value from the key matched by the named
 	// KeyMatcher.
 	Value(matcherName string) string
@@ -50,4 +149,100 @@ type MatchGroupResult interface {
 	// KeyMatcher, or returns the fallback value if the target KeyMatcher did not
 	// match any keys.
 	ValueOr(matcherName, fallback string) string
+
+	// Int parses the environment value from the key matched by the named
+	// KeyMatcher as an int.
+	//
+	// If the named KeyMatcher did not match any keys, a *MissingKeyError is
+	// returned.  If the value could not be parsed, a *ConversionError is
+	// returned.
+	//
+	// All the typed accessors below follow the same error rules.
+	Int(matcherName string) (int, error)
+
+	// IntOr works like Int, but returns the fallback value if the named
+	// KeyMatcher did not match any keys or the value could not be parsed.
+	//
+	// All the typed ...Or accessors below follow the same fallback rules.
+	IntOr(matcherName string, fallback int) int
+
+	// Int64 parses the value matched by the named KeyMatcher as an int64.
+	Int64(matcherName string) (int64, error)
+
+	// Int64Or works like Int64, but returns the fallback value on failure.
+	Int64Or(matcherName string, fallback int64) int64
+
+	// Uint parses the value matched by the named KeyMatcher as a uint.
+	Uint(matcherName string) (uint, error)
+
+	// UintOr works like Uint, but returns the fallback value on failure.
+	UintOr(matcherName string, fallback uint) uint
+
+	// Float parses the value matched by the named KeyMatcher as a float64.
+	Float(matcherName string) (float64, error)
+
+	// FloatOr works like Float, but returns the fallback value on failure.
+	FloatOr(matcherName string, fallback float64) float64
+
+	// Bool parses the value matched by the named KeyMatcher as a bool.
+	//
+	// In addition to the values accepted by strconv.ParseBool, the values "yes",
+	// "no", "on" and "off" are accepted in any case.
+	Bool(matcherName string) (bool, error)
+
+	// BoolOr works like Bool, but returns the fallback value on failure.
+	BoolOr(matcherName string, fallback bool) bool
+
+	// Duration parses the value matched by the named KeyMatcher as a
+	// time.Duration using time.ParseDuration.
+	Duration(matcherName string) (time.Duration, error)
+
+	// DurationOr works like Duration, but returns the fallback value on failure.
+	DurationOr(matcherName string, fallback time.Duration) time.Duration
+
+	// Time parses the value matched by the named KeyMatcher as a time.Time using
+	// the given layout.
+	Time(matcherName, layout string) (time.Time, error)
+
+	// TimeOr works like Time, but returns the fallback value on failure.
+	TimeOr(matcherName, layout string, fallback time.Time) time.Time
+
+	// URL parses the value matched by the named KeyMatcher as a URL.
+	URL(matcherName string) (*url.URL, error)
+
+	// URLOr works like URL, but returns the fallback value on failure.
+	URLOr(matcherName string, fallback *url.URL) *url.URL
+
+	// IP parses the value matched by the named KeyMatcher as an IPv4 or IPv6
+	// address.
+	IP(matcherName string) (net.IP, error)
+
+	// IPOr works like IP, but returns the fallback value on failure.
+	IPOr(matcherName string, fallback net.IP) net.IP
+
+	// CIDR parses the value matched by the named KeyMatcher as a CIDR notation
+	// IP network, such as "10.0.0.0/8".
+	CIDR(matcherName string) (*net.IPNet, error)
+
+	// CIDROr works like CIDR, but returns the fallback value on failure.
+	CIDROr(matcherName string, fallback *net.IPNet) *net.IPNet
+
+	// Bytes parses the value matched by the named KeyMatcher as a byte size,
+	// such as "512", "64KB" or "1.5GiB".
+	//
+	// Decimal suffixes (KB, MB, GB, ...) are powers of 1000, while binary
+	// suffixes (KiB, MiB, GiB, ...) and bare unit letters (K, M, G, ...) are
+	// powers of 1024.  Suffixes are case-insensitive.
+	Bytes(matcherName string) (uint64, error)
+
+	// BytesOr works like Bytes, but returns the fallback value on failure.
+	BytesOr(matcherName string, fallback uint64) uint64
+
+	// StringSlice splits the value matched by the named KeyMatcher on the given
+	// separator, trimming whitespace from each element.
+	StringSlice(matcherName, sep string) ([]string, error)
+
+	// StringSliceOr works like StringSlice, but returns the fallback value on
+	// failure.
+	StringSliceOr(matcherName, sep string, fallback []string) []string
 }
@@ -20,14 +20,126 @@ type MatchGroup interface {
 	Name() string
 
 	// AddMatcher adds a new KeyMatcher to this MatchGroup.
+	//
+	// If the given KeyMatcher is a NamedKeyMatcher, the names of its keys must
+	// match the key names of every other NamedKeyMatcher in this MatchGroup.
+	// This is validated when the MatchGroup is compiled.
 	AddMatcher(matcher KeyMatcher, required bool) MatchGroup
 
-	// process processes the given environment key and value.
-	process(key, val string) bool
+	// SetFileIndirection enables file indirection for every KeyMatcher in this
+	// MatchGroup, using the given options.
+	//
+	// This is equivalent to wrapping each KeyMatcher added to the group with
+	// WithFileIndirection.
+	SetFileIndirection(options FileOptions) MatchGroup
 
-	// result returns the processing results of the given environment entries.
-	result() (MatchGroupResults, []error)
+	// AddNamespace claims the given variable name prefix for this MatchGroup.
+	//
+	// Environment variables whose names start with a claimed prefix are expected
+	// to be matched by a KeyMatcher.  Any that are not are listed by
+	// EnvMatchResult.Unmatched, and are reported according to the StrictMode of
+	// the EnvironmentMatcher.
+	AddNamespace(prefix string) MatchGroup
 
-	// release releases resources held by this MatchGroup.
-	release()
+	// SetOverlapPolicy sets how this MatchGroup resolves environment variables
+	// that are matched by more than one of its KeyMatchers.  Defaults to
+	// OverlapAll.
+	//
+	// When the policy is OverlapAll or OverlapReject, compiling the MatchGroup
+	// reports an *OverlapError warning for each pair of KeyMatchers that may
+	// match the same variables.
+	//
+	// Example:
+	//   NewMatchGroup("db").
+	//     AddMatcher(NewPrefixMatcher("pool", "DB_POOL_"), true).
+	//     AddMatcher(NewPrefixMatcher("poolSize", "DB_POOL_SIZE_"), false).
+	//     SetOverlapPolicy(OverlapLongest)
+	//
+	//   // DB_POOL_SIZE_FOO is only matched by poolSize, for the key FOO.
+	SetOverlapPolicy(policy OverlapPolicy) MatchGroup
+
+	// SetPriority sets the priority of this MatchGroup.  Defaults to zero.
+	//
+	// MatchGroups with a higher priority are given the environment variables
+	// before MatchGroups with a lower priority, and MatchGroups with the same
+	// priority are given the variables in the order they were added to the
+	// EnvironmentMatcher.  This matters when the EnvironmentMatcher is in
+	// exclusive mode; see EnvironmentMatcher.SetExclusive.
+	SetPriority(priority int) MatchGroup
+
+	// SetOrder sets the order of the instances in the MatchGroupResults for this
+	// MatchGroup.  Defaults to OrderLexical.
+	SetOrder(order ResultOrder) MatchGroup
+
+	// SetOrderFunc sets a custom comparison function used to order the
+	// instances in the MatchGroupResults for this MatchGroup, replacing the
+	// order set with SetOrder.
+	//
+	// The function must return a negative number if a comes before b, a
+	// positive number if a comes after b, or zero if their order does not
+	// matter.  Instances that compare equal are kept in the order they were
+	// first seen.
+	//
+	// Example:
+	//   NewMatchGroup("db").
+	//     AddMatcher(NewWrappedMatcher("host", "DB_", "_HOST"), true).
+	//     AddMatcher(NewWrappedMatcher("weight", "DB_", "_WEIGHT"), false).
+	//     SetOrderFunc(func(a, b MatchGroupResult) int {
+	//       return b.IntOr("weight", 0) - a.IntOr("weight", 0)
+	//     })
+	SetOrderFunc(compare func(a, b MatchGroupResult) int) MatchGroup
+
+	// SetIndexed makes this MatchGroup an indexed list, using the given options.
+	//
+	// The first key of each instance is treated as its integer index, and the
+	// MatchGroupResults are ordered by index, replacing any order set with
+	// SetOrder or SetOrderFunc.  Instances whose indices are not valid are left
+	// out of the results and reported as *IndexError values, as are gaps in the
+	// indices if the options require the list to be contiguous.
+	//
+	// Example:
+	//   // SERVER_0_HOST, SERVER_1_HOST, ...
+	//   NewMatchGroup("servers").
+	//     AddMatcher(NewWrappedMatcher("host", "SERVER_", "_HOST"), true).
+	//     SetIndexed(IndexOptions{Contiguous: true})
+	SetIndexed(options IndexOptions) MatchGroup
+
+	// AddChild adds a child MatchGroup to this MatchGroup.
+	//
+	// The KeyMatchers of a child MatchGroup extract the keys of the parent
+	// instance followed by keys of their own, and each child instance is
+	// attached to the parent instance whose keys its own keys start with.  The
+	// child results for each parent instance may be retrieved using
+	// MatchGroupResult.Children.
+	//
+	// Environment variables matched by a child MatchGroup are not offered to the
+	// KeyMatchers of its parent.
+	//
+	// If the child MatchGroup is required, then every instance of this
+	// MatchGroup is expected to have at least one child instance, and a
+	// *MissingGroupError is reported for each that does not.
+	//
+	// Example:
+	//   // DB_MAIN_ADDRESS, DB_MAIN_REPLICA_A_HOST, DB_MAIN_REPLICA_B_HOST
+	//   NewMatchGroup("db").
+	//     AddMatcher(NewPatternMatcher("address", "DB_{instance}_ADDRESS"), true).
+	//     AddChild(NewMatchGroup("replicas").
+	//       AddMatcher(NewPatternMatcher("host", "DB_{instance}_REPLICA_{replica}_HOST"), true),
+	//       false)
+	AddChild(child MatchGroup, required bool) MatchGroup
+
+	// SetSecret marks the values of the named KeyMatchers as secret.
+	//
+	// Secret values are replaced with RedactedValue when the results of this
+	// MatchGroup are serialized, such as by json.Marshal or
+	// EnvMatchResult.WriteYAML, and in the messages of *ConversionError values.
+	// The values themselves are still available through MatchGroupResult.
+	//
+	// Every name must be that of a KeyMatcher added to this MatchGroup,
+	// otherwise compiling the MatchGroup fails with a *SecretNameError.
+	SetSecret(matcherNames ...string) MatchGroup
+
+	// compile returns an immutable snapshot of this MatchGroup's current
+	// configuration, or an error if that configuration is not valid.
+	compile() (*compiledMatchGroup, error)
 }
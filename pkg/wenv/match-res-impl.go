@@ -1,8 +1,14 @@
 package wenv
 
 type matchResult struct {
-	raw   string
-	value string
+	raw    string
+	value  string
+	origin Origin
+
+	// indirect is set when the result was matched through file indirection, in
+	// which case file is the path to the file the value is read from.
+	indirect bool
+	file     string
 }
 
 func (m *matchResult) Raw() string {
@@ -12,3 +18,11 @@ func (m *matchResult) Raw() string {
 func (m *matchResult) Value() string {
 	return m.value
 }
+
+func (m *matchResult) Source() Origin {
+	return m.origin
+}
+
+func (m *matchResult) FilePath() string {
+	return m.file
+}
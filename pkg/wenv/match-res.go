@@ -11,4 +11,20 @@ type MatchResult interface {
 
 	// Value returns the value of the matched environment variable.
 	Value() string
+
+	// Source returns the Origin of the matched environment variable, describing
+	// which Source supplied it, and where applicable, the file and line it was
+	// read from.
+	//
+	// Example:
+	//   res.Get("address").Source().String() // ".env:12" or "process environment"
+	Source() Origin
+
+	// FilePath returns the path to the file the value was read from, if the
+	// value was provided through file indirection (see WithFileIndirection).
+	// Otherwise, FilePath returns an empty string.
+	//
+	// When the value was provided through file indirection, Raw returns the name
+	// of the file indirection variable, such as "DB_FOO_PASSWORD_FILE".
+	FilePath() string
 }
@@ -2,8 +2,10 @@ package wenv
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 )
 
 type MatcherErrors []error
@@ -28,6 +30,88 @@ func (m MatcherErrors) Error() string {
 	return fmt.Sprintf("encountered %d environment parsing errors", len(m))
 }
 
+// All returns an iterator over the index and value of each error in this
+// MatcherErrors list, in order.
+//
+// Example:
+//   for _, err := range result.Errors().All() {
+//     log.Println(err)
+//   }
+func (m MatcherErrors) All() iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for i, err := range m {
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+}
+
+// Unwrap returns the errors in this MatcherErrors list, allowing errors.Is and
+// errors.As to inspect each of them.
+func (m MatcherErrors) Unwrap() []error {
+	return m
+}
+
+// Filter returns a new MatcherErrors list containing only the errors for which
+// the given function returns true.
+//
+// If no errors match, Filter returns nil.
+func (m MatcherErrors) Filter(fn func(err error) bool) MatcherErrors {
+	var out MatcherErrors
+
+	for _, err := range m {
+		if fn(err) {
+			out = append(out, err)
+		}
+	}
+
+	return out
+}
+
+// ByKind returns the errors in this list that match the given kind according
+// to errors.Is, such as ErrMissingKey or ErrConversion.
+func (m MatcherErrors) ByKind(kind error) MatcherErrors {
+	return m.Filter(func(err error) bool { return errors.Is(err, kind) })
+}
+
+// ByGroup returns the errors in this list that relate to the named MatchGroup.
+func (m MatcherErrors) ByGroup(groupName string) MatcherErrors {
+	return m.Filter(func(err error) bool {
+		var ge groupError
+		return errors.As(err, &ge) && ge.errorGroup() == groupName
+	})
+}
+
+// ByKey returns the errors in this list that relate to the named KeyMatcher in
+// the named MatchGroup.
+func (m MatcherErrors) ByKey(groupName, matcherName string) MatcherErrors {
+	return m.Filter(func(err error) bool {
+		var ge groupError
+		return errors.As(err, &ge) && ge.errorGroup() == groupName && ge.errorMatcher() == matcherName
+	})
+}
+
+// ErrorsOf returns every error in the given list that is of type E, as
+// determined by errors.As.
+//
+// Example:
+//   for _, missing := range ErrorsOf[*MissingKeyError](result.Errors()) {
+//     log.Printf("group %s is missing %s", missing.Group, missing.Matcher)
+//   }
+func ErrorsOf[E error](errs MatcherErrors) []E {
+	var out []E
+
+	for _, err := range errs {
+		var target E
+		if errors.As(err, &target) {
+			out = append(out, target)
+		}
+	}
+
+	return out
+}
+
 func (m MatcherErrors) WriteLines(w io.Writer) (written int, err error) {
 	var buf *bufio.Writer
 
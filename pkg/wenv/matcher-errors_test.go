@@ -1,6 +1,7 @@
 package wenv_test
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
@@ -36,3 +37,146 @@ func TestMatcherErrors(t *testing.T) {
 		So(sb.String(), ShouldEqual, "hello\nyou\nsmelly\nlittle\nbiscuit")
 	})
 }
+
+func TestMatcherErrorsInspection(t *testing.T) {
+	Convey("MatcherErrors inspection", t, func() {
+		res := wenv.NewEnvironmentMatcher().
+			AddGroup(wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewWrappedMatcher("address", "DB_", "_ADDRESS"), true).
+				AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), true),
+				true,
+			).
+			AddGroup(wenv.NewMatchGroup("cache").
+				AddMatcher(wenv.NewPrefixMatcher("url", "CACHE_URL_"), true),
+				true,
+			).
+			ParseEnv(map[string]string{"DB_FOO_ADDRESS": "somehost", "DB_FOO_PORT": "port"})
+
+		errs := res.Errors()
+		So(errs.Size(), ShouldEqual, 1)
+
+		_, missingErr := res.Get("db").Get(0).Int("user")
+		_, convErr := res.Get("db").Get(0).Int("port")
+
+		errs = append(errs, missingErr, errors.New("something else"), convErr)
+
+		Convey("with errors.Is and errors.As", func() {
+			So(errors.Is(errs, wenv.ErrMissingGroup), ShouldBeTrue)
+			So(errors.Is(errs, wenv.ErrConversion), ShouldBeTrue)
+			So(errors.Is(errs, wenv.ErrFile), ShouldBeFalse)
+
+			var missingGroup *wenv.MissingGroupError
+			So(errors.As(errs, &missingGroup), ShouldBeTrue)
+			So(missingGroup.Group, ShouldEqual, "cache")
+
+			var missingKey *wenv.MissingKeyError
+			So(errors.As(errs, &missingKey), ShouldBeTrue)
+			So(missingKey.Group, ShouldEqual, "db")
+			So(missingKey.Keys, ShouldResemble, []string{"FOO"})
+			So(missingKey.Matcher, ShouldEqual, "user")
+			So(missingKey.Required, ShouldBeFalse)
+		})
+
+		Convey("with filters", func() {
+			So(errs.ByKind(wenv.ErrMissingGroup).Size(), ShouldEqual, 1)
+			So(errs.ByKind(wenv.ErrMissingKey).Size(), ShouldEqual, 1)
+			So(errs.ByKind(wenv.ErrConversion).Size(), ShouldEqual, 1)
+			So(errs.ByKind(wenv.ErrSource), ShouldBeNil)
+
+			So(errs.ByGroup("cache").Size(), ShouldEqual, 1)
+			So(errs.ByGroup("db").Size(), ShouldEqual, 2)
+			So(errs.ByKey("db", "port").Size(), ShouldEqual, 1)
+			So(errs.ByKey("db", "address"), ShouldBeNil)
+
+			So(errs.Filter(func(err error) bool { return err.Error() == "something else" }).Size(), ShouldEqual, 1)
+
+			conversions := wenv.ErrorsOf[*wenv.ConversionError](errs)
+			So(len(conversions), ShouldEqual, 1)
+			So(conversions[0].Variable, ShouldEqual, "DB_FOO_PORT")
+		})
+	})
+}
+
+func TestMatcherErrorsReport(t *testing.T) {
+	Convey("MatcherErrors reports", t, func() {
+		res := wenv.NewEnvironmentMatcher().
+			AddGroup(wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewWrappedMatcher("address", "DB_", "_ADDRESS"), true).
+				AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), true),
+				true,
+			).
+			ParseEnv(map[string]string{"DB_FOO_ADDRESS": "somehost", "DB_BAR_PORT": "port"})
+
+		var convErr error
+		for i := 0; i < res.Get("db").Size(); i++ {
+			if db := res.Get("db").Get(i); db.FirstKey() == "BAR" {
+				_, convErr = db.Int("port")
+			}
+		}
+
+		errs := wenv.MatcherErrors{res.Errors().ByKey("db", "port")[0], convErr, errors.New("other")}
+
+		Convey("as structured entries", func() {
+			report := errs.Report()
+
+			So(len(report), ShouldEqual, 3)
+			So(report[0], ShouldResemble, wenv.ErrorReport{
+				Kind:     wenv.ErrorKindMissingKey,
+				Group:    "db",
+				Keys:     []string{"FOO"},
+				Matcher:  "port",
+				Variable: "DB_FOO_PORT",
+				Message:  "match group db (keys: FOO) does not have a match for required key port: expected variable DB_FOO_PORT",
+				Hint:     "set the environment variable DB_FOO_PORT",
+			})
+			So(report[1].Kind, ShouldEqual, wenv.ErrorKindConversion)
+			So(report[1].Variable, ShouldEqual, "DB_BAR_PORT")
+			So(report[2], ShouldResemble, wenv.ErrorReport{Kind: wenv.ErrorKindOther, Message: "other"})
+		})
+
+		Convey("as JSON", func() {
+			sb := new(strings.Builder)
+			So(errs.WriteJSON(sb), ShouldBeNil)
+
+			var doc struct {
+				Count  int                `json:"count"`
+				Errors []wenv.ErrorReport `json:"errors"`
+			}
+			So(json.Unmarshal([]byte(sb.String()), &doc), ShouldBeNil)
+			So(doc.Count, ShouldEqual, 3)
+			So(doc.Errors, ShouldResemble, errs.Report())
+		})
+
+		Convey("as SARIF", func() {
+			sb := new(strings.Builder)
+			So(errs.WriteSARIF(sb, wenv.SARIFError), ShouldBeNil)
+
+			var doc map[string]any
+			So(json.Unmarshal([]byte(sb.String()), &doc), ShouldBeNil)
+			So(doc["version"], ShouldEqual, "2.1.0")
+
+			run := doc["runs"].([]any)[0].(map[string]any)
+			So(len(run["tool"].(map[string]any)["driver"].(map[string]any)["rules"].([]any)), ShouldEqual, 3)
+
+			results := run["results"].([]any)
+			So(len(results), ShouldEqual, 3)
+			So(results[0].(map[string]any)["ruleId"], ShouldEqual, "missing_key")
+			So(sb.String(), ShouldContainSubstring, `"fullyQualifiedName": "DB_FOO_PORT"`)
+			So(sb.String(), ShouldContainSubstring, `"fullyQualifiedName": "DB_BAR_PORT"`)
+			So(results[0].(map[string]any)["level"], ShouldEqual, "error")
+
+			sb.Reset()
+			So(errs.WriteSARIF(sb, wenv.SARIFWarning), ShouldBeNil)
+			So(sb.String(), ShouldContainSubstring, `"level": "warning"`)
+			So(sb.String(), ShouldNotContainSubstring, `"level": "error"`)
+		})
+
+		Convey("as a detailed message", func() {
+			So(errs.Detail(), ShouldStartWith, "encountered 3 environment parsing errors:\n"+
+				"  - match group db (keys: FOO) does not have a match for required key port: expected variable DB_FOO_PORT\n"+
+				"  - match group db (keys: BAR): cannot convert DB_BAR_PORT (\"port\") to int: ")
+			So(errs.Detail(), ShouldEndWith, "\n  - other")
+			So(wenv.MatcherErrors(nil).Detail(), ShouldEqual, "encountered 0 environment parsing errors")
+		})
+	})
+}
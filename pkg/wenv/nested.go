@@ -0,0 +1,103 @@
+package wenv
+
+// childMarker is the candidate index standing for the KeyMatchers of a group's
+// children (see compiledMatchGroup.process).
+const childMarker = -1
+
+// processChildren offers the given environment variable to each of the group's
+// children, recording any hits in the children's state maps.
+func (m *compiledMatchGroup) processChildren(state *matchGroupMap, v Variable) (matched bool) {
+	if state.children == nil {
+		state.children = make([]*matchGroupMap, len(m.children))
+		for i := range state.children {
+			child := newMatchGroupMap()
+			state.children[i] = &child
+		}
+	}
+
+	for i, child := range m.children {
+		if child.process(state.children[i], v, child.all) {
+			matched = true
+		}
+	}
+
+	return
+}
+
+// childResults attaches the results of the group's children to the given
+// instances, appending the errors for each child result to the errors of the
+// instance it belongs to.
+//
+// Returns the errors that do not belong to any of the given instances.
+func (m *compiledMatchGroup) childResults(state *matchGroupMap, instances []groupInstance) []error {
+	errors := m.assignChildren(state)
+
+	for i := range instances {
+		inst := &instances[i]
+		if inst.result == nil {
+			continue
+		}
+
+		for ci, child := range m.children {
+			var res MatchGroupResults
+
+			if cs := state.children; cs != nil {
+				if ids := cs[ci].owners[inst.result.id]; len(ids) > 0 {
+					var errs []error
+					res, errs = child.result(cs[ci], ids, len(inst.result.keys))
+					inst.errors = append(inst.errors, errs...)
+				}
+			}
+
+			if res != nil && res.Size() > 0 {
+				if inst.result.children == nil {
+					inst.result.children = make(map[string]MatchGroupResults, len(m.children))
+				}
+				inst.result.children[child.name] = res
+			} else if m.childRequired[ci] {
+				inst.errors = append(inst.errors, &MissingGroupError{Group: child.name, Keys: inst.result.keys})
+			}
+		}
+	}
+
+	return errors
+}
+
+// assignChildren assigns each instance recorded in the state maps of the
+// group's children to the instance of the group whose keys are the longest
+// prefix of its own.
+//
+// The state maps of a group's children are shared by every instance of the
+// group, so this is only done once.  Returns the errors found in the children's
+// state maps, along with an *OrphanError for each child instance that could
+// not be assigned.
+func (m *compiledMatchGroup) assignChildren(state *matchGroupMap) (errors []error) {
+	for ci, cs := range state.children {
+		if cs.owners != nil {
+			continue
+		}
+
+		cs.owners = make(map[InstanceID][]InstanceID, len(state.seen))
+		errors = append(errors, cs.errors...)
+
+		for _, id := range cs.seen {
+			keys := cs.keys[id]
+			assigned := false
+
+			for n := len(keys) - 1; n >= 0; n-- {
+				owner := NewInstanceID(keys[:n]...)
+				if _, ok := state.mp[owner]; ok {
+					cs.owners[owner] = append(cs.owners[owner], id)
+					assigned = true
+					break
+				}
+			}
+
+			if !assigned {
+				errors = append(errors, &OrphanError{Group: m.children[ci].name, Parent: m.name, Keys: keys})
+			}
+		}
+	}
+
+	return
+}
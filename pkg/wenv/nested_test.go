@@ -0,0 +1,106 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestNestedGroups(t *testing.T) {
+	Convey("nested match groups", t, func() {
+		newMatcher := func(replicas wenv.MatchGroup, required bool) wenv.EnvironmentMatcher {
+			return wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewPatternMatcher("address", "DB_{instance}_ADDRESS"), true).
+					AddMatcher(wenv.NewPatternMatcher("host", "DB_{instance}_HOST"), false).
+					AddChild(replicas, required),
+					true,
+				)
+		}
+
+		newReplicas := func() wenv.MatchGroup {
+			return wenv.NewMatchGroup("replicas").
+				AddMatcher(wenv.NewPatternMatcher("host", "DB_{instance}_REPLICA_{replica}_HOST"), true).
+				AddMatcher(wenv.NewPatternMatcher("port", "DB_{instance}_REPLICA_{replica}_PORT"), false)
+		}
+
+		Convey("attach child results to their parents", func() {
+			res := newMatcher(newReplicas(), false).ParseEnv(map[string]string{
+				"DB_MAIN_ADDRESS":        "main",
+				"DB_MAIN_REPLICA_A_HOST": "a.example.com",
+				"DB_MAIN_REPLICA_A_PORT": "5432",
+				"DB_MAIN_REPLICA_B_HOST": "b.example.com",
+				"DB_OTHER_ADDRESS":       "other",
+			})
+
+			So(res.Errors(), ShouldBeNil)
+
+			db := res.Get("db")
+			So(db.Size(), ShouldEqual, 2)
+			So(db.Get(0).FirstKey(), ShouldEqual, "MAIN")
+			So(db.Get(0).Has("host"), ShouldBeFalse)
+
+			replicas := db.Get(0).Children("replicas")
+			So(replicas.Size(), ShouldEqual, 2)
+			So(replicas.Get(0).Keys(), ShouldResemble, []string{"MAIN", "A"})
+			So(replicas.Get(0).Key("replica"), ShouldEqual, "A")
+			So(replicas.Get(0).Get("host").Value(), ShouldEqual, "a.example.com")
+			So(replicas.Get(0).Get("port").Value(), ShouldEqual, "5432")
+			So(replicas.Get(1).Key("replica"), ShouldEqual, "B")
+
+			So(db.Get(1).FirstKey(), ShouldEqual, "OTHER")
+			So(db.Get(1).Children("replicas"), ShouldBeNil)
+			So(db.Get(1).Children("unknown"), ShouldBeNil)
+		})
+
+		Convey("report errors", func() {
+			res := newMatcher(newReplicas(), true).ParseEnv(map[string]string{
+				"DB_MAIN_ADDRESS":         "main",
+				"DB_MAIN_REPLICA_A_PORT":  "5432",
+				"DB_OTHER_ADDRESS":        "other",
+				"DB_GHOST_REPLICA_X_HOST": "ghost",
+			})
+
+			So(res.Errors(), ShouldHaveLength, 3)
+
+			var orphan *wenv.OrphanError
+			So(errors.As(res.Errors(), &orphan), ShouldBeTrue)
+			So(orphan.Keys, ShouldResemble, []string{"GHOST", "X"})
+			So(orphan.Error(), ShouldEqual, "match group replicas (keys: GHOST,X) does not belong to any instance of parent group db")
+
+			var missingKey *wenv.MissingKeyError
+			So(errors.As(res.Errors(), &missingKey), ShouldBeTrue)
+			So(missingKey.Variable, ShouldEqual, "DB_MAIN_REPLICA_A_HOST")
+
+			var missingGroup *wenv.MissingGroupError
+			So(errors.As(res.Errors(), &missingGroup), ShouldBeTrue)
+			So(missingGroup.Error(), ShouldEqual, "no environment matches found for environment group replicas (parent keys: OTHER)")
+		})
+
+		Convey("support indexed children", func() {
+			res := newMatcher(newReplicas().SetIndexed(wenv.IndexOptions{Contiguous: true}), false).ParseEnv(map[string]string{
+				"DB_MAIN_ADDRESS":         "main",
+				"DB_MAIN_REPLICA_10_HOST": "ten",
+				"DB_MAIN_REPLICA_0_HOST":  "zero",
+				"DB_MAIN_REPLICA_2_HOST":  "two",
+				"DB_ALT_ADDRESS":          "alt",
+				"DB_ALT_REPLICA_0_HOST":   "alt-zero",
+			})
+
+			So(res.Errors(), ShouldHaveLength, 2)
+			So(res.Errors()[0].Error(), ShouldEqual, "match group replicas is missing index 1")
+			So(res.Errors()[1].Error(), ShouldEqual, "match group replicas is missing indices 3 to 9")
+
+			replicas := res.Get("db").Get(1).Children("replicas")
+			So(replicas.Size(), ShouldEqual, 3)
+
+			idx, _ := replicas.Get(2).Index()
+			So(idx, ShouldEqual, 10)
+
+			So(res.Get("db").Get(0).Children("replicas").Size(), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package wenv
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// ResultOrder defines the order of the MatchGroupResult instances in the
+// MatchGroupResults of a MatchGroup.
+type ResultOrder uint8
+
+const (
+	// OrderLexical orders instances by their keys, compared byte-wise.  This is
+	// the default order.
+	OrderLexical ResultOrder = iota
+
+	// OrderNatural orders instances by their keys, comparing runs of digits by
+	// their numeric value, so that "DB_2" comes before "DB_10".
+	OrderNatural
+
+	// OrderFirstSeen orders instances by the position of the first environment
+	// variable matched for each, in the order the variables were provided.
+	//
+	// Variables given to ParseEnv are provided in order of their names; for
+	// Parse, variables are provided in the order their Sources produce them.
+	OrderFirstSeen
+)
+
+// compareFunc returns the comparison function used to sort instances in the
+// given order, or nil if the instances should be kept in the order they were
+// first seen.
+func (o ResultOrder) compareFunc() func(a, b MatchGroupResult) int {
+	switch o {
+	case OrderNatural:
+		return func(a, b MatchGroupResult) int {
+			return slices.CompareFunc(a.Keys(), b.Keys(), naturalCompare)
+		}
+	case OrderFirstSeen:
+		return nil
+	default:
+		return func(a, b MatchGroupResult) int {
+			return slices.Compare(a.Keys(), b.Keys())
+		}
+	}
+}
+
+// naturalCompare compares the given strings, treating runs of ASCII digits as
+// numbers.  Numbers that are equal in value are ordered by their number of
+// leading zeros.
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			an, ar := splitDigits(a)
+			bn, br := splitDigits(b)
+
+			// Compare the numbers by value, ignoring leading zeros.
+			at, bt := strings.TrimLeft(an, "0"), strings.TrimLeft(bn, "0")
+			if len(at) != len(bt) {
+				return cmp.Compare(len(at), len(bt))
+			}
+			if c := strings.Compare(at, bt); c != 0 {
+				return c
+			}
+			if len(an) != len(bn) {
+				return cmp.Compare(len(an), len(bn))
+			}
+
+			a, b = ar, br
+			continue
+		}
+
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+
+		a, b = a[1:], b[1:]
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// splitDigits splits the given string after its leading run of digits.
+func splitDigits(s string) (digits, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,73 @@
+package wenv_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+// orderedKeys returns the first key of each instance in the given results.
+func orderedKeys(results wenv.MatchGroupResults) []string {
+	out := make([]string, results.Size())
+	for i := range out {
+		out[i] = results.Get(i).FirstKey()
+	}
+	return out
+}
+
+func TestResultOrder(t *testing.T) {
+	Convey("MatchGroupResults order", t, func() {
+		newGroup := func() wenv.MatchGroup {
+			return wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewWrappedMatcher("host", "DB_", "_HOST"), true).
+				AddMatcher(wenv.NewWrappedMatcher("weight", "DB_", "_WEIGHT"), false)
+		}
+
+		source := wenv.NewReaderSource("test", strings.NewReader(strings.Join([]string{
+			"DB_10_HOST=ten",
+			"DB_2_HOST=two",
+			"DB_B_HOST=b",
+			"DB_1_HOST=one",
+			"DB_A_WEIGHT=1",
+			"DB_B_WEIGHT=3",
+			"DB_10_WEIGHT=2",
+		}, "\n")))
+
+		parse := func(group wenv.MatchGroup) wenv.EnvMatchResult {
+			return wenv.NewEnvironmentMatcher().AddGroup(group, true).Parse(source)
+		}
+
+		Convey("is lexical by default", func() {
+			for i := 0; i < 10; i++ {
+				res := parse(newGroup())
+				So(orderedKeys(res.Get("db")), ShouldResemble, []string{"1", "10", "2", "A", "B"})
+			}
+
+			Convey("including errors", func() {
+				res := parse(newGroup())
+				So(res.Errors(), ShouldHaveLength, 1)
+				So(res.Errors()[0].(*wenv.MissingKeyError).Keys, ShouldResemble, []string{"A"})
+			})
+		})
+
+		Convey("may be natural", func() {
+			res := parse(newGroup().SetOrder(wenv.OrderNatural))
+			So(orderedKeys(res.Get("db")), ShouldResemble, []string{"1", "2", "10", "A", "B"})
+		})
+
+		Convey("may be first seen", func() {
+			res := parse(newGroup().SetOrder(wenv.OrderFirstSeen))
+			So(orderedKeys(res.Get("db")), ShouldResemble, []string{"10", "2", "B", "1", "A"})
+		})
+
+		Convey("may use a custom comparator", func() {
+			res := parse(newGroup().SetOrderFunc(func(a, b wenv.MatchGroupResult) int {
+				return b.IntOr("weight", 0) - a.IntOr("weight", 0)
+			}))
+			So(orderedKeys(res.Get("db")), ShouldResemble, []string{"B", "10", "A", "2", "1"})
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package wenv
+
+import "strings"
+
+// OverlapPolicy defines how a MatchGroup resolves an environment variable that
+// is matched by more than one of its KeyMatchers.
+//
+// For example, given the prefix matchers "DB_POOL_" and "DB_POOL_SIZE_", the
+// variable DB_POOL_SIZE_FOO is matched by both: once with the key "SIZE_FOO"
+// and once with the key "FOO".
+type OverlapPolicy uint8
+
+const (
+	// OverlapAll applies every KeyMatcher that matches a variable.  This is the
+	// default policy.
+	OverlapAll OverlapPolicy = iota
+
+	// OverlapLongest applies only the KeyMatcher that matches the most literal
+	// (non-key) characters of the variable name.  Ties are won by the KeyMatcher
+	// that was added to the MatchGroup first.
+	OverlapLongest
+
+	// OverlapFirst applies only the matching KeyMatcher that was added to the
+	// MatchGroup first.
+	OverlapFirst
+
+	// OverlapReject applies none of the matching KeyMatchers, and instead
+	// reports an *AmbiguousMatchError for the variable.
+	OverlapReject
+)
+
+// affixer is implemented by the KeyMatchers in this package whose matches
+// always start and end with fixed literal text.
+type affixer interface {
+	affixes() (prefix, suffix string)
+}
+
+func (p *prefixKeyMatcher) affixes() (string, string)  { return p.prefix, "" }
+func (s *suffixKeyMatcher) affixes() (string, string)  { return "", s.suffix }
+func (w *wrappedKeyMatcher) affixes() (string, string) { return w.prefix, w.suffix }
+func (p *patternKeyMatcher) affixes() (string, string) { return p.prefix, p.suffix }
+
+// canOverlap tests whether there may be a variable name matched by both of the
+// given KeyMatchers.
+//
+// The check is best-effort: KeyMatchers whose literal prefixes and suffixes
+// are compatible are assumed to overlap, and KeyMatchers whose affixes are not
+// known, such as regex and custom KeyMatchers, are assumed not to.  Overlaps
+// involving such KeyMatchers are therefore not detected.
+func canOverlap(a, b KeyMatcher) bool {
+	if fm, ok := a.(*fileKeyMatcher); ok {
+		a = fm.KeyMatcher
+	}
+	if fm, ok := b.(*fileKeyMatcher); ok {
+		b = fm.KeyMatcher
+	}
+
+	aa, ok := a.(affixer)
+	if !ok {
+		return false
+	}
+
+	ba, ok := b.(affixer)
+	if !ok {
+		return false
+	}
+
+	ap, as := aa.affixes()
+	bp, bs := ba.affixes()
+
+	return (strings.HasPrefix(ap, bp) || strings.HasPrefix(bp, ap)) &&
+		(strings.HasSuffix(as, bs) || strings.HasSuffix(bs, as))
+}
+
+// literalLength returns the number of characters in the given variable name
+// that are not part of the given keys.
+func literalLength(name string, keys []string) int {
+	out := len(name)
+	for _, key := range keys {
+		out -= len(key)
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+// overlapInstance returns the result for the instance with the given key.
+func overlapInstance(results wenv.MatchGroupResults, key string) wenv.MatchGroupResult {
+	for i := 0; i < results.Size(); i++ {
+		if results.Get(i).FirstKey() == key {
+			return results.Get(i)
+		}
+	}
+	return nil
+}
+
+func TestOverlapPolicy(t *testing.T) {
+	Convey("overlapping key matchers", t, func() {
+		env := map[string]string{
+			"DB_POOL_FOO":      "pool",
+			"DB_POOL_SIZE_FOO": "10",
+		}
+
+		newGroup := func() wenv.MatchGroup {
+			return wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewPrefixMatcher("pool", "DB_POOL_"), true).
+				AddMatcher(wenv.NewPrefixMatcher("poolSize", "DB_POOL_SIZE_"), false)
+		}
+
+		Convey("with the default policy", func() {
+			matcher, err := wenv.NewEnvironmentMatcher().AddGroup(newGroup(), true).Compile()
+			So(err, ShouldBeNil)
+			So(matcher.Warnings(), ShouldHaveLength, 1)
+			So(errors.Is(matcher.Warnings(), wenv.ErrOverlap), ShouldBeTrue)
+			So(matcher.Warnings()[0].Error(), ShouldEqual, "match group db: key matcher poolSize may match the same environment variables as key matcher pool")
+
+			res := matcher.ParseEnv(env)
+			So(res.Warnings(), ShouldResemble, matcher.Warnings())
+			So(res.Get("db").Size(), ShouldEqual, 2)
+			So(overlapInstance(res.Get("db"), "SIZE_FOO"), ShouldNotBeNil)
+		})
+
+		Convey("with OverlapLongest", func() {
+			matcher, err := wenv.NewEnvironmentMatcher().
+				AddGroup(newGroup().SetOverlapPolicy(wenv.OverlapLongest), true).
+				Compile()
+			So(err, ShouldBeNil)
+			So(matcher.Warnings(), ShouldBeNil)
+
+			res := matcher.ParseEnv(env)
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("db").Size(), ShouldEqual, 1)
+			So(overlapInstance(res.Get("db"), "FOO").Get("pool").Value(), ShouldEqual, "pool")
+			So(overlapInstance(res.Get("db"), "FOO").Get("poolSize").Value(), ShouldEqual, "10")
+		})
+
+		Convey("with OverlapFirst", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(newGroup().SetOverlapPolicy(wenv.OverlapFirst), true).
+				ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Get("db").Size(), ShouldEqual, 2)
+			So(overlapInstance(res.Get("db"), "SIZE_FOO").Get("pool").Value(), ShouldEqual, "10")
+			So(overlapInstance(res.Get("db"), "FOO").Has("poolSize"), ShouldBeFalse)
+		})
+
+		Convey("with OverlapReject", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(newGroup().SetOverlapPolicy(wenv.OverlapReject), true).
+				ParseEnv(env)
+
+			So(res.Warnings(), ShouldHaveLength, 1)
+			So(res.Errors(), ShouldHaveLength, 1)
+
+			var ae *wenv.AmbiguousMatchError
+			So(errors.As(res.Errors(), &ae), ShouldBeTrue)
+			So(ae.Variable, ShouldEqual, "DB_POOL_SIZE_FOO")
+			So(ae.Matchers, ShouldResemble, []string{"pool", "poolSize"})
+			So(res.Get("db").Size(), ShouldEqual, 1)
+		})
+
+		Convey("with matchers that cannot overlap", func() {
+			matcher, err := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewWrappedMatcher("host", "DB_", "_HOST"), true).
+					AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), true).
+					AddMatcher(wenv.NewPatternMatcher("user", "DB_{name}_USER"), true),
+					true,
+				).
+				Compile()
+
+			So(err, ShouldBeNil)
+			So(matcher.Warnings(), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,296 @@
+package wenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces the values of secret KeyMatchers when match results
+// are serialized or reported in errors (see MatchGroup.SetSecret).
+const RedactedValue = "[REDACTED]"
+
+// isSecret returns whether the values of the named KeyMatcher of the given
+// result are secret.
+func isSecret(result MatchGroupResult, matcherName string) bool {
+	m, ok := result.(*matchGroupResult)
+	return ok && m.group.secrets[matcherName]
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Documents
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// instanceDocument is the serialized form of a single MatchGroupResult.
+type instanceDocument struct {
+	Keys     []string                      `json:"keys"`
+	Values   map[string]string             `json:"values"`
+	Raw      map[string]string             `json:"raw"`
+	Children map[string][]instanceDocument `json:"children,omitempty"`
+}
+
+func (e *envMatchResult) document() map[string][]instanceDocument {
+	out := make(map[string][]instanceDocument, len(e.results))
+	for name, res := range e.results {
+		out[name] = groupDocument(res)
+	}
+	return out
+}
+
+func groupDocument(results MatchGroupResults) []instanceDocument {
+	out := make([]instanceDocument, 0, results.Size())
+	for _, res := range results.All() {
+		out = append(out, instanceDocumentOf(res))
+	}
+	return out
+}
+
+func instanceDocumentOf(result MatchGroupResult) instanceDocument {
+	m, ok := result.(*matchGroupResult)
+	if !ok {
+		return instanceDocument{Keys: result.Keys()}
+	}
+
+	out := instanceDocument{
+		Keys:   m.keys,
+		Values: make(map[string]string, len(m.results)),
+		Raw:    make(map[string]string, len(m.results)),
+	}
+
+	for name, res := range m.results {
+		if isSecret(m, name) {
+			out.Values[name] = RedactedValue
+		} else {
+			out.Values[name] = res.Value()
+		}
+		out.Raw[name] = res.Raw()
+	}
+
+	if len(m.children) > 0 {
+		out.Children = make(map[string][]instanceDocument, len(m.children))
+		for name, res := range m.children {
+			out.Children[name] = groupDocument(res)
+		}
+	}
+
+	return out
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    JSON
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+func (e *envMatchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.document())
+}
+
+func (m matchGroupResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(groupDocument(m))
+}
+
+func (m *matchGroupResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(instanceDocumentOf(m))
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    YAML
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+func (e *envMatchResult) WriteYAML(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	doc := e.document()
+
+	if len(doc) == 0 {
+		buf.WriteString("{}\n")
+	}
+
+	for _, name := range sortedKeys(doc) {
+		fmt.Fprintf(buf, "%s:\n", yamlKey(name))
+		writeYAMLInstances(buf, doc[name], "")
+	}
+
+	return buf.Flush()
+}
+
+func writeYAMLInstances(w *bufio.Writer, instances []instanceDocument, indent string) {
+	for _, inst := range instances {
+		fmt.Fprintf(w, "%s  - keys:", indent)
+		if len(inst.Keys) == 0 {
+			w.WriteString(" []\n")
+		} else {
+			w.WriteByte('\n')
+			for _, key := range inst.Keys {
+				fmt.Fprintf(w, "%s      - %s\n", indent, yamlString(key))
+			}
+		}
+
+		writeYAMLMap(w, "values", inst.Values, indent+"    ")
+		writeYAMLMap(w, "raw", inst.Raw, indent+"    ")
+
+		if len(inst.Children) > 0 {
+			fmt.Fprintf(w, "%s    children:\n", indent)
+			for _, name := range sortedKeys(inst.Children) {
+				fmt.Fprintf(w, "%s      %s:\n", indent, yamlKey(name))
+				writeYAMLInstances(w, inst.Children[name], indent+"      ")
+			}
+		}
+	}
+}
+
+func writeYAMLMap(w *bufio.Writer, name string, values map[string]string, indent string) {
+	if len(values) == 0 {
+		fmt.Fprintf(w, "%s%s: {}\n", indent, name)
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s:\n", indent, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s  %s: %s\n", indent, yamlKey(key), yamlString(values[key]))
+	}
+}
+
+// yamlKey returns the given key as a plain YAML scalar if it cannot be mistaken
+// for anything other than a string, otherwise as a double quoted scalar.
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "", "~", "y", "n", "yes", "no", "true", "false", "on", "off", "null":
+		return yamlString(key)
+	}
+
+	for i, c := range key {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '_' || i > 0 && (c >= '0' && c <= '9' || c == '-')) {
+			return yamlString(key)
+		}
+	}
+
+	return key
+}
+
+// yamlString returns the given string as a YAML double quoted scalar.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    TOML
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+func (e *envMatchResult) WriteTOML(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	doc := e.document()
+
+	for _, name := range sortedKeys(doc) {
+		writeTOMLInstances(buf, []string{name}, doc[name])
+	}
+
+	return buf.Flush()
+}
+
+func writeTOMLInstances(w *bufio.Writer, path []string, instances []instanceDocument) {
+	table := tomlPath(path)
+
+	for _, inst := range instances {
+		fmt.Fprintf(w, "[[%s]]\n", table)
+
+		keys := make([]string, len(inst.Keys))
+		for i, key := range inst.Keys {
+			keys[i] = tomlString(key)
+		}
+		fmt.Fprintf(w, "keys = [%s]\n\n", strings.Join(keys, ", "))
+
+		writeTOMLTable(w, table+".values", inst.Values)
+		writeTOMLTable(w, table+".raw", inst.Raw)
+
+		for _, name := range sortedKeys(inst.Children) {
+			writeTOMLInstances(w, append(slices.Clip(path), "children", name), inst.Children[name])
+		}
+	}
+}
+
+func writeTOMLTable(w *bufio.Writer, table string, values map[string]string) {
+	fmt.Fprintf(w, "[%s]\n", table)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s = %s\n", tomlKey(key), tomlString(values[key]))
+	}
+	w.WriteByte('\n')
+}
+
+// tomlPath joins the given keys into a dotted TOML key.
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlKey returns the given key as a bare TOML key if possible, otherwise as a
+// quoted key.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+
+	for _, c := range key {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return tomlString(key)
+		}
+	}
+
+	return key
+}
+
+// tomlString returns the given string as a TOML basic string.
+func tomlString(s string) string {
+	sb := new(strings.Builder)
+	sb.WriteByte('"')
+
+	for _, c := range s {
+		switch c {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(sb, `\u%04X`, c)
+			} else {
+				sb.WriteRune(c)
+			}
+		}
+	}
+
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,197 @@
+package wenv_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestSerialization(t *testing.T) {
+	Convey("EnvMatchResult serialization", t, func() {
+		res := wenv.NewEnvironmentMatcher().
+			AddGroup(wenv.NewMatchGroup("db").
+				AddMatcher(wenv.NewPatternMatcher("address", "DB_{instance}_ADDRESS"), true).
+				AddMatcher(wenv.NewPatternMatcher("password", "DB_{instance}_PASSWORD"), false).
+				SetSecret("password").
+				AddChild(wenv.NewMatchGroup("replicas").
+					AddMatcher(wenv.NewPatternMatcher("host", "DB_{instance}_REPLICA_{replica}_HOST"), true),
+					false,
+				),
+				true,
+			).
+			ParseEnv(map[string]string{
+				"DB_MAIN_ADDRESS":        "main \"db\"",
+				"DB_MAIN_PASSWORD":       "hunter2",
+				"DB_MAIN_REPLICA_A_HOST": "a.example.com",
+			})
+
+		So(res.Errors(), ShouldBeNil)
+
+		Convey("to JSON", func() {
+			out, err := json.Marshal(res)
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `{"db":[{"keys":["MAIN"],"values":{"address":"main \"db\"","password":"[REDACTED]"},"raw":{"address":"DB_MAIN_ADDRESS","password":"DB_MAIN_PASSWORD"},"children":{"replicas":[{"keys":["MAIN","A"],"values":{"host":"a.example.com"},"raw":{"host":"DB_MAIN_REPLICA_A_HOST"}}]}}]}`)
+
+			out, err = json.Marshal(res.Get("db").Get(0).Children("replicas"))
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `[{"keys":["MAIN","A"],"values":{"host":"a.example.com"},"raw":{"host":"DB_MAIN_REPLICA_A_HOST"}}]`)
+
+			So(res.Get("db").Get(0).Value("password"), ShouldEqual, "hunter2")
+		})
+
+		Convey("to YAML", func() {
+			sb := new(strings.Builder)
+			So(res.WriteYAML(sb), ShouldBeNil)
+			So(sb.String(), ShouldEqual, strings.Join([]string{
+				`db:`,
+				`  - keys:`,
+				`      - "MAIN"`,
+				`    values:`,
+				`      address: "main \"db\""`,
+				`      password: "[REDACTED]"`,
+				`    raw:`,
+				`      address: "DB_MAIN_ADDRESS"`,
+				`      password: "DB_MAIN_PASSWORD"`,
+				`    children:`,
+				`      replicas:`,
+				`        - keys:`,
+				`            - "MAIN"`,
+				`            - "A"`,
+				`          values:`,
+				`            host: "a.example.com"`,
+				`          raw:`,
+				`            host: "DB_MAIN_REPLICA_A_HOST"`,
+				``,
+			}, "\n"))
+		})
+
+		Convey("to TOML", func() {
+			sb := new(strings.Builder)
+			So(res.WriteTOML(sb), ShouldBeNil)
+			So(sb.String(), ShouldEqual, strings.Join([]string{
+				`[[db]]`,
+				`keys = ["MAIN"]`,
+				``,
+				`[db.values]`,
+				`address = "main \"db\""`,
+				`password = "[REDACTED]"`,
+				``,
+				`[db.raw]`,
+				`address = "DB_MAIN_ADDRESS"`,
+				`password = "DB_MAIN_PASSWORD"`,
+				``,
+				`[[db.children.replicas]]`,
+				`keys = ["MAIN", "A"]`,
+				``,
+				`[db.children.replicas.values]`,
+				`host = "a.example.com"`,
+				``,
+				`[db.children.replicas.raw]`,
+				`host = "DB_MAIN_REPLICA_A_HOST"`,
+				``,
+				``,
+			}, "\n"))
+		})
+
+		Convey("of struct groups", func() {
+			type Database struct {
+				Address  string `wenv:"ADDRESS"`
+				Password string `wenv:"PASSWORD,secret"`
+			}
+
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.GroupFromStruct[Database]("db", wenv.Wrapped("DB_", "_{field}")), true).
+				ParseEnv(map[string]string{"DB_FOO_ADDRESS": "foo", "DB_FOO_PASSWORD": "hunter2"})
+
+			out, err := json.Marshal(res.Get("db").Get(0))
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `{"keys":["FOO"],"values":{"ADDRESS":"foo","PASSWORD":"[REDACTED]"},"raw":{"ADDRESS":"DB_FOO_ADDRESS","PASSWORD":"DB_FOO_PASSWORD"}}`)
+
+			dbs, err := wenv.Bind[Database](res, "db")
+			So(err, ShouldBeNil)
+			So(dbs[0].Password, ShouldEqual, "hunter2")
+		})
+
+		Convey("of secret values in conversion errors", func() {
+			_, err := res.Get("db").Get(0).Int("password")
+			So(err, ShouldNotBeNil)
+			So(err.(*wenv.ConversionError).Secret, ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "match group db (keys: MAIN): cannot convert DB_MAIN_PASSWORD ([REDACTED]) to int: value is not valid (details redacted)")
+			So(err.(*wenv.ConversionError).Value, ShouldBeEmpty)
+			So(fmt.Sprintf("%+v", err), ShouldNotContainSubstring, "hunter2")
+
+			var numErr *strconv.NumError
+			So(errors.As(err, &numErr), ShouldBeFalse)
+
+			sb := new(strings.Builder)
+			So(wenv.MatcherErrors{err}.WriteJSON(sb), ShouldBeNil)
+			So(sb.String(), ShouldContainSubstring, wenv.RedactedValue)
+			So(sb.String(), ShouldNotContainSubstring, "hunter2")
+
+			type Database struct {
+				Password int `wenv:"PASSWORD,secret"`
+			}
+
+			_, err = wenv.Bind[Database](wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.GroupFromStruct[Database]("db", wenv.Wrapped("DB_", "_{field}")), true).
+				ParseEnv(map[string]string{"DB_FOO_PASSWORD": "hunter2"}), "db")
+
+			var convErr *wenv.ConversionError
+			So(errors.As(err, &convErr), ShouldBeTrue)
+			So(convErr.Error(), ShouldEqual, "match group db (keys: FOO): cannot convert DB_FOO_PASSWORD ([REDACTED]) to int for field Password: value is not valid (details redacted)")
+
+			_, err = wenv.Bind[Database](wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewPatternMatcher("PASSWORD", "DB_{instance}_PASSWORD"), true),
+					true,
+				).
+				ParseEnv(map[string]string{"DB_FOO_PASSWORD": "hunter2"}), "db")
+			So(errors.As(err, &numErr), ShouldBeFalse)
+			So(errors.As(err, &convErr), ShouldBeTrue)
+			So(convErr.Value, ShouldBeEmpty)
+
+			_, err = res.Get("db").Get(0).Int("address")
+			So(err.Error(), ShouldContainSubstring, `main \"db\"`)
+		})
+
+		Convey("of empty results", func() {
+			res := wenv.NewEnvironmentMatcher().ParseEnv(nil)
+
+			out, err := json.Marshal(res)
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, `{}`)
+
+			sb := new(strings.Builder)
+			So(res.WriteYAML(sb), ShouldBeNil)
+			So(sb.String(), ShouldEqual, "{}\n")
+
+			sb.Reset()
+			So(res.WriteTOML(sb), ShouldBeNil)
+			So(sb.String(), ShouldEqual, "")
+		})
+
+		Convey("with an unknown secret name", func() {
+			_, err := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewPatternMatcher("password", "DB_{instance}_PASSWORD"), true).
+					SetSecret("pasword"),
+					true,
+				).
+				Compile()
+
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, wenv.ErrInvalidGroup), ShouldBeTrue)
+
+			var nameErr *wenv.SecretNameError
+			So(errors.As(err, &nameErr), ShouldBeTrue)
+			So(nameErr.Error(), ShouldEqual, "match group db: cannot mark unknown key matcher pasword as secret")
+		})
+	})
+}
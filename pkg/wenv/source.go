@@ -0,0 +1,446 @@
+package wenv
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Source provides environment variables to be parsed by an
+// EnvironmentMatcher.
+//
+// Example:
+//   result := NewEnvironmentMatcher().
+//     AddGroup(group, true).
+//     Parse(NewDotenvSource(".env"), NewProcessSource())
+type Source interface {
+	// Name returns a short, human readable description of this Source, such as
+	// "process environment" or a file path.
+	Name() string
+
+	// Load reads all the variables provided by this Source.
+	Load() ([]Variable, error)
+}
+
+// Variable is a single environment variable read from a Source.
+type Variable struct {
+	// Name is the name of the environment variable.
+	Name string
+
+	// Value is the value of the environment variable.
+	Value string
+
+	// Origin describes where the variable was read from.
+	Origin Origin
+}
+
+// Origin describes where a Variable was read from.
+type Origin struct {
+	// Source is the name of the Source the variable was read from.
+	Source string
+
+	// Path is the path to the file the variable was read from, if any.
+	Path string
+
+	// Line is the line number the variable was defined on, if any.  Line
+	// numbers start at 1; a value of 0 means the line number is not known.
+	Line int
+}
+
+// String returns the Origin formatted as "path:line", "path", or the name of
+// the Source, depending on which details are known.
+func (o Origin) String() string {
+	switch {
+	case o.Path != "" && o.Line > 0:
+		return fmt.Sprintf("%s:%d", o.Path, o.Line)
+	case o.Path != "":
+		return o.Path
+	default:
+		return o.Source
+	}
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Layered Source
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// NewLayeredSource returns a Source that merges the variables provided by the
+// given layers.
+//
+// Layers are given in order of increasing precedence: a variable provided by a
+// later layer replaces a variable of the same name provided by an earlier
+// layer.  The Origin of each merged Variable is that of the layer that
+// supplied its final value, so the MatchResult built from it reports which
+// layer it came from.
+//
+// If any of the layers fail to load, the remaining layers are still loaded and
+// merged, and the errors are returned as a MatcherErrors value.
+//
+// Example:
+//   source := NewLayeredSource(
+//     NewDotenvSource("defaults.env"),
+//     NewDotenvSource("production.env"),
+//     NewProcessSource(),
+//   )
+func NewLayeredSource(layers ...Source) Source {
+	return &layeredSource{layers}
+}
+
+type layeredSource struct {
+	layers []Source
+}
+
+func (l *layeredSource) Name() string {
+	names := make([]string, len(l.layers))
+	for i, layer := range l.layers {
+		names[i] = layer.Name()
+	}
+
+	return "layered(" + strings.Join(names, ", ") + ")"
+}
+
+func (l *layeredSource) Load() ([]Variable, error) {
+	if vars, errs := mergeLayers(l.layers); len(errs) > 0 {
+		return vars, MatcherErrors(errs)
+	} else {
+		return vars, nil
+	}
+}
+
+// mergeLayers loads the given layers in order, with variables from later layers
+// replacing variables of the same name from earlier layers.
+//
+// Nested layered sources are flattened so that their layer errors are reported
+// individually.
+func mergeLayers(layers []Source) ([]Variable, []error) {
+	var errors []error
+
+	out := make([]Variable, 0, 64)
+	index := make(map[string]int, 64)
+
+	for _, vars := range flattenLayers(layers, &errors) {
+		for _, v := range vars {
+			if i, ok := index[v.Name]; ok {
+				out[i] = v
+			} else {
+				index[v.Name] = len(out)
+				out = append(out, v)
+			}
+		}
+	}
+
+	return out, errors
+}
+
+func flattenLayers(layers []Source, errors *[]error) [][]Variable {
+	out := make([][]Variable, 0, len(layers))
+
+	for _, layer := range layers {
+		if nested, ok := layer.(*layeredSource); ok {
+			out = append(out, flattenLayers(nested.layers, errors)...)
+			continue
+		}
+
+		vars, err := layer.Load()
+		if err != nil {
+			*errors = append(*errors, &SourceError{layer.Name(), err})
+		}
+
+		out = append(out, vars)
+	}
+
+	return out
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Process Source
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+const processSourceName = "process environment"
+
+// NewProcessSource returns a Source that provides the environment of the
+// current process, as returned by os.Environ.
+func NewProcessSource() Source {
+	return processSource{}
+}
+
+type processSource struct{}
+
+func (processSource) Name() string {
+	return processSourceName
+}
+
+func (processSource) Load() ([]Variable, error) {
+	env := os.Environ()
+	out := make([]Variable, 0, len(env))
+
+	for _, pair := range env {
+		name, value, _ := strings.Cut(pair, "=")
+		out = append(out, Variable{name, value, Origin{Source: processSourceName}})
+	}
+
+	return out, nil
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Map Source
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+const mapSourceName = "map"
+
+// NewMapSource returns a Source that provides the variables in the given map,
+// in the same form as the map returned by SplitEnvironment.
+//
+// Variables are provided in order of their names.
+func NewMapSource(env map[string]string) Source {
+	return mapSource(env)
+}
+
+type mapSource map[string]string
+
+func (mapSource) Name() string {
+	return mapSourceName
+}
+
+func (m mapSource) Load() ([]Variable, error) {
+	return mapVariables(m, mapSourceName), nil
+}
+
+// mapVariables converts the given environment map into a slice of Variables
+// sorted by name.
+func mapVariables(env map[string]string, source string) []Variable {
+	out := make([]Variable, 0, len(env))
+
+	for name, value := range env {
+		out = append(out, Variable{name, value, Origin{Source: source}})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    Dotenv Sources
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// NewDotenvSource returns a Source that provides the variables defined in the
+// dotenv file at the given path.  See ParseDotenv for the supported syntax.
+//
+// The file is read every time the Source is loaded.
+func NewDotenvSource(path string) Source {
+	return &dotenvFileSource{path}
+}
+
+type dotenvFileSource struct{ path string }
+
+func (d *dotenvFileSource) Name() string {
+	return d.path
+}
+
+func (d *dotenvFileSource) Load() ([]Variable, error) {
+	file, err := os.Open(d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readDotenv(file, Origin{Source: d.path, Path: d.path})
+}
+
+// NewReaderSource returns a Source that provides the variables defined in the
+// dotenv formatted content of the given reader (see ParseDotenv).  The given
+// name is used as the Source's Name.
+//
+// The reader is consumed the first time the Source is loaded, and the result is
+// reused for any later loads.
+func NewReaderSource(name string, reader io.Reader) Source {
+	return &readerSource{name: name, reader: reader}
+}
+
+type readerSource struct {
+	name   string
+	reader io.Reader
+
+	once sync.Once
+	vars []Variable
+	err  error
+}
+
+func (r *readerSource) Name() string {
+	return r.name
+}
+
+func (r *readerSource) Load() ([]Variable, error) {
+	r.once.Do(func() {
+		r.vars, r.err = readDotenv(r.reader, Origin{Source: r.name})
+		r.reader = nil
+	})
+
+	return r.vars, r.err
+}
+
+// readDotenv parses the dotenv formatted content of the given reader (see
+// ParseDotenv) into Variables with the given Origin.
+func readDotenv(reader io.Reader, origin Origin) ([]Variable, error) {
+	entries, err := parseDotenv(reader, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Variable, len(entries))
+	for i, entry := range entries {
+		origin.Line = entry.line
+		out[i] = Variable{entry.name, entry.value, origin}
+	}
+
+	return out, nil
+}
+
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+//
+//    FS Source
+//
+// // // // // // // // // // // // // // // // // // // // // // // // // // //
+
+// NewFSSource returns a Source that provides one variable for each file in the
+// given directory of the given file system.  Each file's name is used as the
+// variable name, and the file's contents are used as the variable value.
+//
+// Subdirectories and files whose names start with '.' are skipped.
+//
+// Example:
+//   // Given the files /etc/app/env/DB_FOO_ADDRESS and /etc/app/env/DB_FOO_PORT
+//   source := NewFSSource(os.DirFS("/etc/app"), "env")
+func NewFSSource(fsys fs.FS, dir string) Source {
+	return &fsSource{fsys: fsys, dir: dir, root: dir}
+}
+
+// kubernetesDataDir is the name of the symlink Kubernetes points at the current
+// snapshot of a mounted ConfigMap or Secret volume.
+const kubernetesDataDir = "..data"
+
+// NewVolumeSource returns a Source that provides one variable for each file in
+// the directory at the given path, such as a Kubernetes ConfigMap or Secret
+// mounted as a volume.  Each file's name is used as the variable name, and the
+// file's contents are used as the variable value.
+//
+// Kubernetes mounts these volumes as a set of symlinks pointing into a "..data"
+// directory, which is itself a symlink to the current timestamped snapshot of
+// the volume.  If the "..data" directory is present, the variables are read
+// from it directly so that they all come from the same snapshot, even if the
+// volume is updated while it is being read.
+//
+// Files holding values are often written with a trailing newline.  If
+// trimNewline is true, a single trailing "\n" or "\r\n" will be removed from
+// each value.
+//
+// As with NewFSSource, subdirectories and files whose names start with '.' are
+// skipped.
+//
+// Example:
+//   // Given a secret volume mounted at /etc/secrets/db containing the files
+//   // DB_FOO_PASSWORD and DB_BAR_PASSWORD.
+//   source := NewVolumeSource("/etc/secrets/db", true)
+func NewVolumeSource(path string, trimNewline bool) Source {
+	return &fsSource{
+		fsys:        os.DirFS(path),
+		dir:         ".",
+		root:        path,
+		trimNewline: trimNewline,
+		followData:  true,
+	}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	dir  string
+
+	// root is the directory path used for the Name of the source and the Origin
+	// paths of its variables.
+	root string
+
+	trimNewline bool
+	followData  bool
+}
+
+func (f *fsSource) Name() string {
+	return f.root
+}
+
+func (f *fsSource) Load() ([]Variable, error) {
+	dir := f.dir
+
+	if f.followData {
+		if info, err := fs.Stat(f.fsys, path.Join(dir, kubernetesDataDir)); err == nil && info.IsDir() {
+			dir = path.Join(dir, kubernetesDataDir)
+		}
+	}
+
+	entries, err := fs.ReadDir(f.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Variable, 0, len(entries))
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		file := path.Join(dir, entry.Name())
+
+		// Stat the file rather than checking the entry so that symlinks to
+		// directories are skipped as well.
+		if info, err := fs.Stat(f.fsys, file); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			continue
+		}
+
+		value, err := fs.ReadFile(f.fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.trimNewline {
+			value = trimTrailingNewline(value)
+		}
+
+		out = append(out, Variable{
+			Name:   entry.Name(),
+			Value:  string(value),
+			Origin: Origin{Source: f.root, Path: filepath.Join(f.root, entry.Name())},
+		})
+	}
+
+	return out, nil
+}
+
+// trimTrailingNewline removes a single trailing "\n" or "\r\n" from the given
+// value.
+func trimTrailingNewline(value []byte) []byte {
+	if n := len(value); n > 0 && value[n-1] == '\n' {
+		value = value[:n-1]
+		if n > 1 && value[n-2] == '\r' {
+			value = value[:n-2]
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,206 @@
+package wenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func sourceTestMatcher() wenv.EnvironmentMatcher {
+	return wenv.NewEnvironmentMatcher().
+		AddGroup(wenv.NewMatchGroup("db").
+			AddMatcher(wenv.NewWrappedMatcher("address", "DB_", "_ADDRESS"), true).
+			AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), false),
+			true,
+		)
+}
+
+func TestSources(t *testing.T) {
+	Convey("sources", t, func() {
+		Convey("process source", func() {
+			t.Setenv("DB_PROCESS_ADDRESS", "somehost")
+
+			vars, err := wenv.NewProcessSource().Load()
+			So(err, ShouldBeNil)
+
+			found := false
+			for _, v := range vars {
+				if v.Name == "DB_PROCESS_ADDRESS" {
+					found = true
+					So(v.Value, ShouldEqual, "somehost")
+					So(v.Origin.String(), ShouldEqual, "process environment")
+				}
+			}
+			So(found, ShouldBeTrue)
+
+			res := sourceTestMatcher().Parse(wenv.NewProcessSource())
+			So(res.Get("db").Get(0).Value("address"), ShouldEqual, "somehost")
+		})
+
+		Convey("map source", func() {
+			vars, err := wenv.NewMapSource(map[string]string{"B": "2", "A": "1"}).Load()
+
+			So(err, ShouldBeNil)
+			So(vars, ShouldResemble, []wenv.Variable{
+				{Name: "A", Value: "1", Origin: wenv.Origin{Source: "map"}},
+				{Name: "B", Value: "2", Origin: wenv.Origin{Source: "map"}},
+			})
+		})
+
+		Convey("dotenv source", func() {
+			file := filepath.Join(t.TempDir(), ".env")
+			So(os.WriteFile(file, []byte("# comment\n\nDB_FOO_ADDRESS=somehost\nDB_FOO_PORT = 1234\n"), 0600), ShouldBeNil)
+
+			vars, err := wenv.NewDotenvSource(file).Load()
+			So(err, ShouldBeNil)
+			So(len(vars), ShouldEqual, 2)
+			So(vars[1].Name, ShouldEqual, "DB_FOO_PORT")
+			So(vars[1].Value, ShouldEqual, "1234")
+			So(vars[1].Origin.String(), ShouldEqual, file+":4")
+
+			_, err = wenv.NewDotenvSource(filepath.Join(t.TempDir(), "missing")).Load()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("reader source", func() {
+			source := wenv.NewReaderSource("stdin", strings.NewReader("DB_FOO_ADDRESS=somehost\n"))
+
+			for i := 0; i < 2; i++ {
+				vars, err := source.Load()
+				So(err, ShouldBeNil)
+				So(vars, ShouldResemble, []wenv.Variable{{Name: "DB_FOO_ADDRESS", Value: "somehost", Origin: wenv.Origin{Source: "stdin", Line: 1}}})
+			}
+
+			_, err := wenv.NewReaderSource("stdin", strings.NewReader("NOT A VARIABLE")).Load()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("fs source", func() {
+			fsys := fstest.MapFS{
+				"env/DB_FOO_ADDRESS": {Data: []byte("somehost")},
+				"env/.hidden":        {Data: []byte("hidden")},
+				"env/sub/DB_FOO_BAR": {Data: []byte("nested")},
+			}
+
+			vars, err := wenv.NewFSSource(fsys, "env").Load()
+			So(err, ShouldBeNil)
+			So(vars, ShouldResemble, []wenv.Variable{{Name: "DB_FOO_ADDRESS", Value: "somehost", Origin: wenv.Origin{Source: "env", Path: "env/DB_FOO_ADDRESS"}}})
+		})
+
+		Convey("volume source", func() {
+			dir := t.TempDir()
+			snapshot := filepath.Join(dir, "..2024_01_02_03_04_05.000000001")
+
+			So(os.Mkdir(snapshot, 0700), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(snapshot, "DB_FOO_ADDRESS"), []byte("somehost\n"), 0600), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(snapshot, "DB_FOO_PORT"), []byte("1234\r\n\n"), 0600), ShouldBeNil)
+			So(os.Symlink(filepath.Base(snapshot), filepath.Join(dir, "..data")), ShouldBeNil)
+			So(os.Symlink(filepath.Join("..data", "DB_FOO_ADDRESS"), filepath.Join(dir, "DB_FOO_ADDRESS")), ShouldBeNil)
+			So(os.Symlink(filepath.Join("..data", "DB_FOO_PORT"), filepath.Join(dir, "DB_FOO_PORT")), ShouldBeNil)
+
+			Convey("trimming newlines", func() {
+				vars, err := wenv.NewVolumeSource(dir, true).Load()
+
+				So(err, ShouldBeNil)
+				So(vars, ShouldResemble, []wenv.Variable{
+					{Name: "DB_FOO_ADDRESS", Value: "somehost", Origin: wenv.Origin{Source: dir, Path: filepath.Join(dir, "DB_FOO_ADDRESS")}},
+					{Name: "DB_FOO_PORT", Value: "1234\r\n", Origin: wenv.Origin{Source: dir, Path: filepath.Join(dir, "DB_FOO_PORT")}},
+				})
+
+				res := sourceTestMatcher().Parse(wenv.NewVolumeSource(dir, true))
+				So(res.Errors(), ShouldBeNil)
+				So(res.Get("db").Get(0).Value("address"), ShouldEqual, "somehost")
+			})
+
+			Convey("without trimming newlines", func() {
+				vars, err := wenv.NewVolumeSource(dir, false).Load()
+
+				So(err, ShouldBeNil)
+				So(vars[0].Value, ShouldEqual, "somehost\n")
+			})
+
+			Convey("without a ..data directory", func() {
+				plain := t.TempDir()
+				So(os.WriteFile(filepath.Join(plain, "DB_FOO_ADDRESS"), []byte("plainhost"), 0600), ShouldBeNil)
+
+				vars, err := wenv.NewVolumeSource(plain, true).Load()
+
+				So(err, ShouldBeNil)
+				So(len(vars), ShouldEqual, 1)
+				So(vars[0].Value, ShouldEqual, "plainhost")
+			})
+		})
+
+		Convey("parsing multiple sources", func() {
+			res := sourceTestMatcher().Parse(
+				wenv.NewMapSource(map[string]string{"DB_FOO_ADDRESS": "somehost", "DB_FOO_PORT": "1234"}),
+				wenv.NewReaderSource("overrides", strings.NewReader("DB_FOO_PORT=4321")),
+				wenv.NewDotenvSource(filepath.Join(t.TempDir(), "missing")),
+			)
+
+			So(res.Get("db").Size(), ShouldEqual, 1)
+			So(res.Get("db").Get(0).Value("address"), ShouldEqual, "somehost")
+			So(res.Get("db").Get(0).Value("port"), ShouldEqual, "4321")
+
+			So(res.Errors().Size(), ShouldEqual, 1)
+			So(res.Errors().Get(0).Error(), ShouldStartWith, "failed to load environment source ")
+		})
+
+		Convey("layered source", func() {
+			dir := t.TempDir()
+			defaults := filepath.Join(dir, "defaults.env")
+			overrides := filepath.Join(dir, "production.env")
+
+			So(os.WriteFile(defaults, []byte("DB_FOO_ADDRESS=localhost\nDB_FOO_PORT=5432\n"), 0600), ShouldBeNil)
+			So(os.WriteFile(overrides, []byte("# production\nDB_FOO_ADDRESS=db.example.com\n"), 0600), ShouldBeNil)
+
+			t.Setenv("DB_BAR_ADDRESS", "bar.example.com")
+
+			source := wenv.NewLayeredSource(
+				wenv.NewDotenvSource(defaults),
+				wenv.NewDotenvSource(overrides),
+				wenv.NewProcessSource(),
+			)
+
+			So(source.Name(), ShouldEqual, "layered("+defaults+", "+overrides+", process environment)")
+
+			res := sourceTestMatcher().Parse(source)
+			So(res.Errors(), ShouldBeNil)
+
+			for i := 0; i < res.Get("db").Size(); i++ {
+				db := res.Get("db").Get(i)
+
+				switch db.FirstKey() {
+				case "FOO":
+					So(db.Value("address"), ShouldEqual, "db.example.com")
+					So(db.Get("address").Source().String(), ShouldEqual, overrides+":2")
+					So(db.Get("port").Source().String(), ShouldEqual, defaults+":2")
+					So(db.Get("port").Source().Line, ShouldEqual, 2)
+				case "BAR":
+					So(db.Get("address").Source().String(), ShouldEqual, "process environment")
+				}
+			}
+
+			Convey("reporting each failed layer", func() {
+				source := wenv.NewLayeredSource(
+					wenv.NewDotenvSource(filepath.Join(dir, "missing-1.env")),
+					wenv.NewLayeredSource(wenv.NewDotenvSource(filepath.Join(dir, "missing-2.env"))),
+					wenv.NewDotenvSource(defaults),
+				)
+
+				_, err := source.Load()
+				So(err.(wenv.MatcherErrors).Size(), ShouldEqual, 2)
+
+				res := sourceTestMatcher().Parse(source)
+				So(res.Errors().Size(), ShouldEqual, 2)
+				So(res.Get("db").Get(0).Value("address"), ShouldEqual, "localhost")
+			})
+		})
+	})
+}
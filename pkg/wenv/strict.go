@@ -0,0 +1,45 @@
+package wenv
+
+import "strings"
+
+// StrictMode defines how environment variables that fall under a claimed
+// namespace but are not matched by any KeyMatcher are reported.
+//
+// See EnvironmentMatcher.AddNamespace and MatchGroup.AddNamespace.
+type StrictMode uint8
+
+const (
+	// StrictOff does not report unmatched variables.  They are still listed by
+	// EnvMatchResult.Unmatched.
+	StrictOff StrictMode = iota
+
+	// StrictWarn reports an *UnmatchedVariableError for each unmatched variable
+	// in the warnings of the EnvMatchResult.
+	StrictWarn
+
+	// StrictError reports an *UnmatchedVariableError for each unmatched
+	// variable in the errors of the EnvMatchResult.
+	StrictError
+)
+
+// namespace is a variable name prefix claimed by an EnvironmentMatcher or by
+// one of its MatchGroups.
+type namespace struct {
+	prefix string
+
+	// group is the name of the MatchGroup that claimed the namespace, or an
+	// empty string if it was claimed by the EnvironmentMatcher itself.
+	group string
+}
+
+// claim returns the first of the given namespaces the named variable falls
+// under, if any.
+func claim(namespaces []namespace, name string) (namespace, bool) {
+	for _, ns := range namespaces {
+		if strings.HasPrefix(name, ns.prefix) {
+			return ns, true
+		}
+	}
+
+	return namespace{}, false
+}
@@ -0,0 +1,89 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestStrictMode(t *testing.T) {
+	Convey("strict mode", t, func() {
+		env := map[string]string{
+			"DB_FOO_HOST":   "foo",
+			"DB_FOO_PORT":   "5432",
+			"DB_FOO_HSOT":   "typo",
+			"APP_DEBUG":     "true",
+			"APP_NAME_MAIN": "example",
+			"UNRELATED_VAR": "x",
+		}
+
+		newMatcher := func() wenv.EnvironmentMatcher {
+			return wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddNamespace("DB_").
+					AddMatcher(wenv.NewWrappedMatcher("host", "DB_", "_HOST"), true).
+					AddMatcher(wenv.NewWrappedMatcher("port", "DB_", "_PORT"), true),
+					true,
+				).
+				AddGroup(wenv.NewMatchGroup("app").
+					AddMatcher(wenv.NewPrefixMatcher("name", "APP_NAME_"), false),
+					false,
+				).
+				AddNamespace("APP_")
+		}
+
+		Convey("when disabled", func() {
+			res := newMatcher().ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Warnings(), ShouldBeNil)
+			So(res.Unmatched(), ShouldHaveLength, 2)
+			So(res.Unmatched()[0].Name, ShouldEqual, "APP_DEBUG")
+			So(res.Unmatched()[1].Name, ShouldEqual, "DB_FOO_HSOT")
+		})
+
+		Convey("with warnings", func() {
+			res := newMatcher().SetStrictMode(wenv.StrictWarn).ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Warnings(), ShouldHaveLength, 2)
+			So(errors.Is(res.Warnings(), wenv.ErrUnmatched), ShouldBeTrue)
+			So(res.Warnings()[0].Error(), ShouldEqual, "environment variable APP_DEBUG in namespace APP_ was not matched by any key matcher")
+			So(res.Warnings()[1].Error(), ShouldEqual, "environment variable DB_FOO_HSOT in namespace DB_ of match group db was not matched by any key matcher")
+		})
+
+		Convey("with errors", func() {
+			res := newMatcher().SetStrictMode(wenv.StrictError).ParseEnv(env)
+
+			So(res.Warnings(), ShouldBeNil)
+			So(res.Errors(), ShouldHaveLength, 2)
+			So(res.Errors().ByGroup("db"), ShouldHaveLength, 1)
+
+			errs := wenv.ErrorsOf[*wenv.UnmatchedVariableError](res.Errors())
+			So(errs, ShouldHaveLength, 2)
+			So(errs[1].Variable, ShouldEqual, "DB_FOO_HSOT")
+			So(errs[1].Namespace, ShouldEqual, "DB_")
+			So(errs[1].Group, ShouldEqual, "db")
+
+			report := res.Errors().Report()
+			So(report[1].Kind, ShouldEqual, wenv.ErrorKindUnmatched)
+			So(report[1].Variable, ShouldEqual, "DB_FOO_HSOT")
+		})
+
+		Convey("without namespaces", func() {
+			res := wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("app").
+					AddMatcher(wenv.NewPrefixMatcher("name", "APP_NAME_"), false),
+					false,
+				).
+				SetStrictMode(wenv.StrictError).
+				ParseEnv(env)
+
+			So(res.Errors(), ShouldBeNil)
+			So(res.Unmatched(), ShouldBeNil)
+		})
+	})
+}
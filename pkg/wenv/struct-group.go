@@ -0,0 +1,188 @@
+package wenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldPlaceholder is the placeholder replaced with a field's tag name in the
+// templates given to the built-in FieldLayout constructors.
+const fieldPlaceholder = "{field}"
+
+// A FieldLayout describes how the environment variable names for the fields of
+// a struct are laid out, and is used by GroupFromStruct to build a KeyMatcher
+// for each of those fields.
+type FieldLayout interface {
+	// Matcher returns a new KeyMatcher with the given name that matches the
+	// environment variables for the given field name.
+	Matcher(name, field string) (KeyMatcher, error)
+}
+
+// Prefix returns a FieldLayout that builds prefix KeyMatchers (see
+// NewPrefixMatcher) from the given template.
+//
+// The template must contain the string "{field}", which will be replaced by
+// each field's name.
+//
+// Example:
+//   // Matches PLUGIN_NAME_ORANGE, PLUGIN_PATH_ORANGE, etc.
+//   layout := Prefix("PLUGIN_{field}_")
+func Prefix(template string) FieldLayout {
+	return &prefixLayout{template}
+}
+
+type prefixLayout struct{ template string }
+
+func (p *prefixLayout) Matcher(name, field string) (KeyMatcher, error) {
+	if !strings.Contains(p.template, fieldPlaceholder) {
+		return nil, missingFieldError(p.template)
+	}
+
+	return NewPrefixMatcher(name, strings.ReplaceAll(p.template, fieldPlaceholder, field)), nil
+}
+
+// Suffix returns a FieldLayout that builds suffix KeyMatchers (see
+// NewSuffixMatcher) from the given template.
+//
+// The template must contain the string "{field}", which will be replaced by
+// each field's name.
+//
+// Example:
+//   // Matches ORANGE_PLUGIN_NAME, ORANGE_PLUGIN_PATH, etc.
+//   layout := Suffix("_PLUGIN_{field}")
+func Suffix(template string) FieldLayout {
+	return &suffixLayout{template}
+}
+
+type suffixLayout struct{ template string }
+
+func (s *suffixLayout) Matcher(name, field string) (KeyMatcher, error) {
+	if !strings.Contains(s.template, fieldPlaceholder) {
+		return nil, missingFieldError(s.template)
+	}
+
+	return NewSuffixMatcher(name, strings.ReplaceAll(s.template, fieldPlaceholder, field)), nil
+}
+
+// Wrapped returns a FieldLayout that builds wrapped KeyMatchers (see
+// NewWrappedMatcher) from the given prefix and suffix templates.
+//
+// At least one of the templates must contain the string "{field}", which will
+// be replaced by each field's name.
+//
+// Example:
+//   // Matches DB_MAIN_ADDRESS, DB_MAIN_PORT, etc.
+//   layout := Wrapped("DB_", "_{field}")
+func Wrapped(prefix, suffix string) FieldLayout {
+	return &wrappedLayout{prefix, suffix}
+}
+
+type wrappedLayout struct{ prefix, suffix string }
+
+func (w *wrappedLayout) Matcher(name, field string) (KeyMatcher, error) {
+	if !strings.Contains(w.prefix, fieldPlaceholder) && !strings.Contains(w.suffix, fieldPlaceholder) {
+		return nil, fmt.Errorf("invalid layout: neither prefix %q nor suffix %q contains %s", w.prefix, w.suffix, fieldPlaceholder)
+	}
+
+	return NewWrappedMatcher(
+		name,
+		strings.ReplaceAll(w.prefix, fieldPlaceholder, field),
+		strings.ReplaceAll(w.suffix, fieldPlaceholder, field),
+	), nil
+}
+
+// Pattern returns a FieldLayout that builds pattern KeyMatchers (see
+// NewPatternMatcher) from the given template.
+//
+// The template must contain the string "{field}", which will be replaced by
+// each field's name before the template is parsed, so "field" may not be used
+// as a placeholder name.
+//
+// Example:
+//   // Matches DB_MAIN_REPLICA_A_HOST, DB_MAIN_REPLICA_A_PORT, etc.
+//   layout := Pattern("DB_{instance}_REPLICA_{replica}_{field}")
+func Pattern(template string) FieldLayout {
+	return &patternLayout{template}
+}
+
+type patternLayout struct{ template string }
+
+func (p *patternLayout) Matcher(name, field string) (KeyMatcher, error) {
+	if !strings.Contains(p.template, fieldPlaceholder) {
+		return nil, missingFieldError(p.template)
+	}
+
+	return ParsePatternMatcher(name, strings.ReplaceAll(p.template, fieldPlaceholder, field))
+}
+
+// missingFieldError returns the error reported for a layout template that does
+// not contain the field placeholder, which would otherwise build the same
+// KeyMatcher for every field.
+func missingFieldError(template string) error {
+	return fmt.Errorf("invalid layout: template %q does not contain %s", template, fieldPlaceholder)
+}
+
+// GroupFromStruct builds a new MatchGroup with the given name from the `wenv`
+// tagged fields of the struct type T.
+//
+// One KeyMatcher is added to the group for each tagged field, built by the
+// given FieldLayout.  The tag name is used both as the name of the KeyMatcher
+// and as the field name substituted into the layout, so the same struct type
+// may be used with Bind to read the results back out.  See Bind for the tag
+// syntax.
+//
+// Fields tagged as required are added to the group as required KeyMatchers,
+// unless they also have a default value.
+//
+// This function panics if T is not a struct type, if any of its tags are not
+// valid, or if the layout fails to build a KeyMatcher.  Use
+// ParseGroupFromStruct to handle these errors without panicking.
+//
+// Example:
+//   type Database struct {
+//     Address string `wenv:"ADDRESS,required"`
+//     Port    int    `wenv:"PORT,default=5432"`
+//   }
+//
+//   result := NewEnvironmentMatcher().
+//     AddGroup(GroupFromStruct[Database]("db", Wrapped("DB_", "_{field}")), true).
+//     ParseEnv(SplitEnvironment(os.Environ()))
+//
+//   databases, err := Bind[Database](result, "db")
+func GroupFromStruct[T any](name string, layout FieldLayout) MatchGroup {
+	if group, err := ParseGroupFromStruct[T](name, layout); err != nil {
+		panic(err)
+	} else {
+		return group
+	}
+}
+
+// ParseGroupFromStruct builds a new MatchGroup with the given name from the
+// `wenv` tagged fields of the struct type T, returning an error if the
+// MatchGroup could not be built.
+//
+// See GroupFromStruct for details.
+func ParseGroupFromStruct[T any](name string, layout FieldLayout) (MatchGroup, error) {
+	plan, err := bindPlanFor(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	group := NewMatchGroup(name)
+
+	for _, field := range plan.fields {
+		matcher, err := layout.Matcher(field.tag.name, field.tag.name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build key matcher for field %s: %w", field.field, err)
+		}
+
+		group.AddMatcher(matcher, field.tag.required && !field.tag.hasDefault)
+
+		if field.tag.secret {
+			group.SetSecret(field.tag.name)
+		}
+	}
+
+	return group, nil
+}
@@ -0,0 +1,146 @@
+package wenv
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultTypoDistance is the maximum edit distance used by typo detection when
+// TypoOptions.MaxDistance is zero.
+const DefaultTypoDistance = 2
+
+// TypoOptions configures typo detection for an EnvironmentMatcher.
+//
+// When typo detection is enabled, every environment variable that was not
+// matched by any MatchGroup is compared against the variable name expected by
+// each *MissingKeyError produced by a parse.  Unmatched variables that are
+// close to the expected name are attached to the error as suggestions.
+type TypoOptions struct {
+	// MaxDistance is the maximum number of single character insertions,
+	// deletions or substitutions that may separate an unmatched variable from an
+	// expected name for the variable to be suggested.  Defaults to
+	// DefaultTypoDistance.
+	MaxDistance int
+}
+
+func (t TypoOptions) maxDistance() int {
+	if t.MaxDistance <= 0 {
+		return DefaultTypoDistance
+	}
+	return t.MaxDistance
+}
+
+// suggest attaches typo suggestions drawn from the given unmatched variables to
+// every *MissingKeyError in the given errors that names an expected variable.
+func (t TypoOptions) suggest(errors []error, unmatched []Variable) {
+	if len(unmatched) == 0 {
+		return
+	}
+
+	for _, err := range errors {
+		mk, ok := err.(*MissingKeyError)
+		if !ok || mk.Variable == "" {
+			continue
+		}
+
+		mk.Suggestions = t.candidates(mk.Variable, unmatched)
+	}
+}
+
+// candidates returns the names of the given variables that are likely typos of
+// the given expected name, closest first.
+func (t TypoOptions) candidates(expected string, vars []Variable) []string {
+	type candidate struct {
+		name string
+		dist int
+	}
+
+	limit := t.maxDistance()
+	var found []candidate
+
+	for _, v := range vars {
+		// Only consider variables that look like they belong to the same group
+		// as the expected name; that is, ones that share its leading or trailing
+		// name segment.
+		if !sharesAffix(expected, v.Name) {
+			continue
+		}
+
+		if d := editDistance(expected, v.Name, limit); d > 0 && d <= limit {
+			found = append(found, candidate{v.Name, d})
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].name < found[j].name
+	})
+
+	out := make([]string, len(found))
+	for i := range found {
+		out[i] = found[i].name
+	}
+
+	return out
+}
+
+// sharesAffix tests whether the given names share the same first or last
+// underscore separated segment.  Names without any separators always match.
+func sharesAffix(a, b string) bool {
+	i := strings.IndexByte(a, '_')
+	if i == -1 {
+		return true
+	}
+
+	if strings.HasPrefix(b, a[:i+1]) {
+		return true
+	}
+
+	return strings.HasSuffix(b, a[strings.LastIndexByte(a, '_'):])
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+//
+// Once the distance is known to exceed limit, the calculation stops early and a
+// value greater than limit is returned.
+func editDistance(a, b string, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		best := curr[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			best = min(best, curr[j])
+		}
+
+		if best > limit {
+			return limit + 1
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
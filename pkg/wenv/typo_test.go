@@ -0,0 +1,76 @@
+package wenv_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/foxcapades/go-wildcard-env/pkg/wenv"
+)
+
+func TestTypoDetection(t *testing.T) {
+	Convey("typo detection", t, func() {
+		newMatcher := func() wenv.EnvironmentMatcher {
+			return wenv.NewEnvironmentMatcher().
+				AddGroup(wenv.NewMatchGroup("db").
+					AddMatcher(wenv.NewWrappedMatcher("host", "DB_", "_HOST"), true).
+					AddMatcher(wenv.NewWrappedMatcher("address", "DB_", "_ADDRESS"), true),
+					true,
+				)
+		}
+
+		env := map[string]string{
+			"DB_FOO_HOST":    "foo",
+			"DB_FOO_ADRESS":  "10.0.0.1",
+			"DB_FOO_ADDRES":  "10.0.0.2",
+			"XX_FOO_ADDRESX": "10.0.0.3",
+			"DB_FOO_PORT":    "5432",
+		}
+
+		Convey("when disabled", func() {
+			res := newMatcher().ParseEnv(env)
+
+			var mk *wenv.MissingKeyError
+			So(errors.As(res.Errors(), &mk), ShouldBeTrue)
+			So(mk.Suggestions, ShouldBeNil)
+		})
+
+		Convey("when enabled", func() {
+			res := newMatcher().SetTypoDetection(wenv.TypoOptions{}).ParseEnv(env)
+
+			var mk *wenv.MissingKeyError
+			So(errors.As(res.Errors(), &mk), ShouldBeTrue)
+			So(mk.Variable, ShouldEqual, "DB_FOO_ADDRESS")
+			So(mk.Suggestions, ShouldResemble, []string{"DB_FOO_ADDRES", "DB_FOO_ADRESS"})
+			So(mk.Error(), ShouldEqual, "match group db (keys: FOO) does not have a match for required key address: expected variable DB_FOO_ADDRESS (did you mean DB_FOO_ADDRES, DB_FOO_ADRESS?)")
+
+			report := wenv.MatcherErrors{mk}.Report()
+			So(report[0].Hint, ShouldEqual, "set the environment variable DB_FOO_ADDRESS or rename DB_FOO_ADDRES, DB_FOO_ADRESS")
+		})
+
+		Convey("with a custom distance", func() {
+			res := newMatcher().SetTypoDetection(wenv.TypoOptions{MaxDistance: 4}).ParseEnv(map[string]string{
+				"DB_FOO_HOST":   "foo",
+				"DB_FOO_ADRS":   "10.0.0.1",
+				"DB_FOO_PORT":   "5432",
+				"DB_FOO_STRESS": "high",
+			})
+
+			var mk *wenv.MissingKeyError
+			So(errors.As(res.Errors(), &mk), ShouldBeTrue)
+			So(mk.Suggestions, ShouldResemble, []string{"DB_FOO_ADRS", "DB_FOO_STRESS"})
+		})
+
+		Convey("with a compiled matcher", func() {
+			matcher, err := newMatcher().SetTypoDetection(wenv.TypoOptions{MaxDistance: 1}).Compile()
+			So(err, ShouldBeNil)
+
+			res := matcher.ParseEnv(env)
+
+			var mk *wenv.MissingKeyError
+			So(errors.As(res.Errors(), &mk), ShouldBeTrue)
+			So(mk.Suggestions, ShouldResemble, []string{"DB_FOO_ADDRES", "DB_FOO_ADRESS"})
+		})
+	})
+}
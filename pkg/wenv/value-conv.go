@@ -0,0 +1,139 @@
+package wenv
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func parseInt(value string) (int, error) {
+	return strconv.Atoi(value)
+}
+
+func parseInt64(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseUint(value string) (uint, error) {
+	u, err := strconv.ParseUint(value, 10, 0)
+	return uint(u), err
+}
+
+func parseFloat(value string) (float64, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseBool parses boolean values, accepting the common environment variable
+// spellings "yes", "no", "on" and "off" in addition to the values accepted by
+// strconv.ParseBool.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(value)
+	}
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	return time.ParseDuration(value)
+}
+
+func parseURL(value string) (*url.URL, error) {
+	return url.Parse(value)
+}
+
+func parseIP(value string) (net.IP, error) {
+	if ip := net.ParseIP(value); ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", value)
+	} else {
+		return ip, nil
+	}
+}
+
+func parseCIDR(value string) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(value)
+	return ipNet, err
+}
+
+func parseStringSlice(value, sep string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	out := strings.Split(value, sep)
+	for i := range out {
+		out[i] = strings.TrimSpace(out[i])
+	}
+
+	return out
+}
+
+// byteUnits maps lower case size suffixes to their multipliers.
+var byteUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"pib": 1 << 50,
+}
+
+// parseBytes parses a byte size such as "512", "64KB", "1.5GiB" or "10 m".
+//
+// Decimal suffixes (KB, MB, ...) are powers of 1000, binary suffixes (KiB, MiB,
+// ...) and bare unit letters (K, M, ...) are powers of 1024.  Suffixes are case
+// insensitive.
+func parseBytes(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+
+	i := 0
+	for i < len(value) && (value[i] >= '0' && value[i] <= '9' || value[i] == '.') {
+		i++
+	}
+
+	if i == 0 {
+		return 0, fmt.Errorf("invalid byte size %q", value)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(value[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unrecognized unit %q", value, strings.TrimSpace(value[i:]))
+	}
+
+	// Parse whole numbers as integers to avoid losing precision on large sizes.
+	if n, err := strconv.ParseUint(value[:i], 10, 64); err == nil {
+		if n > math.MaxUint64/unit {
+			return 0, fmt.Errorf("invalid byte size %q: value out of range", value)
+		}
+		return n * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", value)
+	}
+
+	if f *= float64(unit); f >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid byte size %q: value out of range", value)
+	}
+
+	return uint64(f), nil
+}